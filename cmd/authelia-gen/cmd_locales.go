@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/text/feature/plural"
 	"golang.org/x/text/language"
 	"golang.org/x/text/language/display"
+	"gopkg.in/yaml.v3"
 
 	"github.com/authelia/authelia/v4/internal/utils"
 )
@@ -25,6 +30,13 @@ func newLocalesCmd() *cobra.Command {
 		DisableAutoGenTag: true,
 	}
 
+	cmd.Flags().String(cmdFlagLocaleDefault, localeDefault, "The locale of the default language")
+	cmd.Flags().String(cmdFlagLocaleNamespaceDefault, localeNamespaceDefault, "The default namespace")
+	cmd.Flags().StringToString(cmdFlagLocaleDisplay, nil, "Overrides the display name of a locale")
+	cmd.Flags().Float64(cmdFlagLocaleCoverageMinimum, 0, "The minimum translation coverage percentage of a language, languages below it are reported as warnings and hidden")
+	cmd.Flags().Bool(cmdFlagLocaleHidden, false, "Includes the hidden languages for preview")
+	cmd.Flags().StringSlice(cmdFlagLocaleIgnore, localeIgnoreDefault, "The patterns of the directory and root file names to ignore in the locales directory")
+
 	return cmd
 }
 
@@ -34,6 +46,8 @@ func localesRunE(cmd *cobra.Command, args []string) (err error) {
 		pathWebI18NIndex, pathDocsDataLanguages string
 	)
 
+	var opts languagesOptions
+
 	if root, err = cmd.Flags().GetString(cmdFlagRoot); err != nil {
 		return err
 	}
@@ -50,11 +64,23 @@ func localesRunE(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	data, err := getLanguages(filepath.Join(root, pathLocales))
+	if opts, err = getLanguagesOptions(cmd); err != nil {
+		return err
+	}
+
+	data, err := getLanguagesFromFS(os.DirFS(filepath.Join(root, pathLocales)), opts)
 	if err != nil {
 		return err
 	}
 
+	for _, warning := range data.Warnings {
+		cmd.PrintErrf("warning: %s\n", warning)
+	}
+
+	if err = getLanguagesServedErr(data); err != nil {
+		return err
+	}
+
 	fullPathWebI18NIndex := filepath.Join(root, pathWebI18NIndex)
 
 	var (
@@ -88,133 +114,949 @@ func localesRunE(cmd *cobra.Command, args []string) (err error) {
 	return nil
 }
 
-//nolint:gocyclo
-func getLanguages(dir string) (languages *Languages, err error) {
-	//nolint:prealloc
-	var locales []string
+// getLanguagesServedErr returns an error if a language backed by locale files can't be served by the server, which only
+// serves the JSON locale files in the single directory of each locale i.e. it doesn't serve the flat layout or YAML.
+func getLanguagesServedErr(languages *Languages) (err error) {
+	for _, l := range languages.Languages {
+		var names, dirs []string
+
+		for _, file := range l.files {
+			names = append(names, file.name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			dir := path.Dir(name)
+
+			if dir == "." {
+				return fmt.Errorf("the locale '%s' has the locale file '%s' which can't be served as only locale files in the directory of the locale are served", l.Locale, name)
+			}
+
+			if path.Ext(name) != extJSON {
+				return fmt.Errorf("the locale '%s' has the locale file '%s' which can't be served as only JSON locale files are served", l.Locale, name)
+			}
+
+			if !utils.IsStringInSlice(dir, dirs) {
+				dirs = append(dirs, dir)
+			}
+		}
+
+		sort.Strings(dirs)
+
+		switch {
+		case len(dirs) > 1:
+			return fmt.Errorf("the locale '%s' has locale files in the directories '%s' but only a single directory can be served for each locale", l.Locale, strings.Join(dirs, "', '"))
+		case len(dirs) == 1 && getLocaleReal(dirs[0]) != l.Locale:
+			return fmt.Errorf("the locale '%s' has locale files in the directory '%s' which can't be served for the locale", l.Locale, dirs[0])
+		}
+	}
+
+	return nil
+}
+
+// localeExtensions are the file extensions recognized as locale files.
+var localeExtensions = []string{extJSON, extYAML, extYML}
+
+// localeIgnoreDefault is the default patterns of the directories which are not locale directories.
+var localeIgnoreDefault = []string{".*", "_*"}
+
+// localeAliases are the built-in aliases of macrolanguage locales to the locale they're served as. Deprecated locales
+// such as iw and in are not included as they're already replaced with he and id when parsed.
+var localeAliases = map[string]string{
+	"no": "nb",
+}
+
+// getLanguagesOptions returns the options used to load the languages from the flags of the provided command.
+func getLanguagesOptions(cmd *cobra.Command) (opts languagesOptions, err error) {
+	opts = languagesOptions{Validate: true, Coverage: true}
+
+	if opts.DefaultLocale, err = cmd.Flags().GetString(cmdFlagLocaleDefault); err != nil {
+		return opts, err
+	}
+
+	if opts.DefaultNamespace, err = cmd.Flags().GetString(cmdFlagLocaleNamespaceDefault); err != nil {
+		return opts, err
+	}
+
+	if opts.Display, err = cmd.Flags().GetStringToString(cmdFlagLocaleDisplay); err != nil {
+		return opts, err
+	}
+
+	if opts.CoverageMinimum, err = cmd.Flags().GetFloat64(cmdFlagLocaleCoverageMinimum); err != nil {
+		return opts, err
+	}
+
+	if opts.IncludeHidden, err = cmd.Flags().GetBool(cmdFlagLocaleHidden); err != nil {
+		return opts, err
+	}
+
+	if opts.Ignore, err = cmd.Flags().GetStringSlice(cmdFlagLocaleIgnore); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// languagesOptions alters the behaviour of getLanguagesFromFS.
+type languagesOptions struct {
+	// Validate enables reading every locale file and reporting files which fail to parse, stray files in a locale
+	// directory, files in the root directory with a locale which fails to parse, and languages missing namespaces of
+	// the default language as warnings on the returned Languages. The locale files which fail to parse are skipped.
+	// Validation never causes loading the languages to fail, see Lenient for the locales which fail to parse.
+	Validate bool
+
+	// Coverage enables computing the translation coverage of each language backed by locale files relative to the
+	// default language.
+	Coverage bool
+
+	// DefaultLocale is the locale of the default language which all fallbacks terminate at. Defaults to the en locale.
+	DefaultLocale string
+
+	// DefaultNamespace is the default namespace. Defaults to the portal namespace.
+	DefaultNamespace string
+
+	// Ignore is the patterns matched against the name of each directory and each file in the root directory, the
+	// directories which match are skipped along with their contents and the files which match are skipped. Defaults to
+	// the names prefixed with a dot or underscore.
+	Ignore []string
+
+	// Lenient skips the locale directories with a locale which fails to parse instead of failing to load the languages.
+	// The skipped locales are returned on the Languages and each is reported as a warning.
+	Lenient bool
+
+	// Failures remembers the locales which have been skipped in lenient mode across loads so each is only reported as
+	// a warning the first time it's skipped.
+	Failures *localeFailures
+
+	// Aliases adds to or overrides the built-in locale aliases by locale.
+	Aliases map[string]string
+
+	// Display overrides the computed display name of a language by locale, including synthesized parent languages.
+	Display map[string]string
+
+	// CoverageMinimum is the minimum coverage percentage, languages below it are reported as warnings and are marked
+	// as hidden along with the synthesized parent languages, unless they're a fallback of a visible language. The
+	// default language is never hidden. Only used when Coverage is enabled.
+	CoverageMinimum float64
+
+	// IncludeHidden includes the languages marked as hidden in the returned Languages instead of excluding them, which
+	// is useful to preview incomplete translations.
+	IncludeHidden bool
+}
+
+// getLanguagesFromFS discovers the languages from the locale files in the provided fs.FS. The expected layout is either
+// a directory per locale containing a file per namespace, or files in the root directory named after both the locale
+// and the namespace.
+func getLanguagesFromFS(fsys fs.FS, opts languagesOptions) (languages *Languages, err error) {
+	return getLanguagesFromFSes([]fs.FS{fsys}, opts)
+}
+
+// getLanguagesFromFSes discovers and merges the languages from the locale files in each of the provided fs.FS. Sources
+// are applied in order, so when more than one source provides the same namespace for the same locale the file from the
+// later source is the one recorded for that locale and namespace. The display name of a locale is always computed from
+// its tag so it does not depend on which source provided it. The parent locales and fallbacks are computed once over
+// the merged set of languages.
+func getLanguagesFromFSes(fsyses []fs.FS, opts languagesOptions) (languages *Languages, err error) {
+	if opts.DefaultLocale == "" {
+		opts.DefaultLocale = localeDefault
+	}
+
+	if opts.DefaultNamespace == "" {
+		opts.DefaultNamespace = localeNamespaceDefault
+	}
+
+	if opts.Ignore == nil {
+		opts.Ignore = localeIgnoreDefault
+	}
+
+	for _, pattern := range opts.Ignore {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("failed to parse ignore pattern '%s': %w", pattern, err)
+		}
+	}
 
 	languages = &Languages{
 		Defaults: DefaultsLanguages{
-			Namespace: localeNamespaceDefault,
+			Namespace: opts.DefaultNamespace,
 		},
+		Aliases: getLocaleAliases(opts),
 	}
 
 	var defaultTag language.Tag
 
-	if defaultTag, err = language.Parse(localeDefault); err != nil {
+	if defaultTag, err = language.Parse(getLocaleReal(opts.DefaultLocale)); err != nil {
 		return nil, fmt.Errorf("failed to parse default language: %w", err)
 	}
 
+	for _, fsys := range fsyses {
+		if err = discoverLanguages(fsys, opts, languages); err != nil {
+			return nil, err
+		}
+	}
+
+	defaultLang, ok := languages.lookup(defaultTag, true)
+	if !ok {
+		return nil, fmt.Errorf("failed to find the locale files of the default language '%s'", opts.DefaultLocale)
+	}
+
+	// The default locale is matched by tag so it's the same as the locale of the default language.
+	opts.DefaultLocale = defaultLang.Locale
+
 	languages.Defaults.Language = Language{
-		Display: display.English.Tags().Name(defaultTag),
-		Locale:  localeDefault,
+		Display:   display.English.Tags().Name(defaultTag),
+		Locale:    defaultLang.Locale,
+		Canonical: defaultTag.String(),
+		Direction: getLanguageDirection(defaultTag),
+		Plurals:   getLanguagePlurals(defaultTag),
+		Tag:       defaultTag,
 	}
 
-	if err = filepath.Walk(dir, func(path string, info fs.FileInfo, errWalk error) (err error) {
-		if errWalk != nil {
-			return errWalk
+	setLanguagesFallbacks(languages)
+
+	sortLanguages(languages, opts)
+
+	setLanguagesDisplay(languages, opts)
+
+	if opts.Validate {
+		for _, missing := range languages.MissingNamespaces() {
+			languages.Warnings = append(languages.Warnings, fmt.Sprintf("language '%s' is missing the namespaces '%s' which are present in the default language", missing.Locale, strings.Join(missing.Namespaces, "', '")))
 		}
+	}
 
-		nameLower := strings.ToLower(info.Name())
-		ext := filepath.Ext(nameLower)
-		ns := strings.Replace(nameLower, ext, "", 1)
+	if opts.Coverage {
+		if err = getLanguagesCoverage(languages, opts); err != nil {
+			return nil, err
+		}
 
-		if ext != extJSON {
-			return nil
+		hideLanguages(languages, opts)
+	}
+
+	return languages, nil
+}
+
+// setLanguagesFallbacks sets the fallbacks of each language and adds the synthesized parent languages which are
+// missing, so every locale in a fallback chain is one of the languages.
+func setLanguagesFallbacks(languages *Languages) {
+	for _, lang := range languages.Languages {
+		for _, p := range getLanguageParents(lang.Tag) {
+			if _, ok := languages.lookup(p, false); ok {
+				continue
+			}
+
+			languages.Languages = append(languages.Languages, Language{
+				Display:    display.English.Tags().Name(p),
+				Locale:     p.String(),
+				Canonical:  p.String(),
+				Direction:  getLanguageDirection(p),
+				Namespaces: lang.Namespaces,
+				Plurals:    getLanguagePlurals(p),
+				Tag:        p,
+			})
 		}
+	}
 
-		if !utils.IsStringInSlice(ns, languages.Namespaces) {
-			languages.Namespaces = append(languages.Namespaces, ns)
+	for i, lang := range languages.Languages {
+		languages.Languages[i].Fallbacks = getLanguageFallbacks(languages, lang.Tag)
+	}
+}
+
+// sortLanguages sorts the languages alphabetically with the default language first, and sorts the namespaces.
+func sortLanguages(languages *Languages, opts languagesOptions) {
+	sort.Slice(languages.Languages, func(i, j int) bool {
+		switch {
+		case languages.Languages[i].Locale == opts.DefaultLocale:
+			return languages.Languages[j].Locale != opts.DefaultLocale
+		case languages.Languages[j].Locale == opts.DefaultLocale:
+			return false
+		default:
+			return languages.Languages[i].Locale < languages.Languages[j].Locale
+		}
+	})
+
+	sortNamespaces(languages.Namespaces, opts.DefaultNamespace)
+
+	for _, lang := range languages.Languages {
+		sortNamespaces(lang.Namespaces, opts.DefaultNamespace)
+	}
+}
+
+// setLanguagesDisplay overrides the display names of the languages with the configured display names.
+func setLanguagesDisplay(languages *Languages, opts languagesOptions) {
+	if display, ok := opts.Display[languages.Defaults.Language.Locale]; ok {
+		languages.Defaults.Language.Display = display
+	}
+
+	for i, lang := range languages.Languages {
+		if display, ok := opts.Display[lang.Locale]; ok {
+			languages.Languages[i].Display = display
+		}
+	}
+}
+
+// getLanguagesCoverage computes the percentage of the message keys of the default language which are present for each
+// language and namespace. Synthesized parent languages are not backed by any locale files so they have no coverage.
+func getLanguagesCoverage(languages *Languages, opts languagesOptions) (err error) {
+	var keysDefault map[string][]string
+
+	if keysDefault, err = getLanguageKeys(languages.Default()); err != nil {
+		return err
+	}
+
+	for i, lang := range languages.Languages {
+		if len(lang.files) == 0 {
+			continue
+		}
+
+		var keys map[string][]string
+
+		if keys, err = getLanguageKeys(lang); err != nil {
+			return err
+		}
+
+		coverage := &LanguageCoverage{
+			Namespaces: map[string]float64{},
+		}
+
+		var present, total int
+
+		for ns, nsKeysDefault := range keysDefault {
+			n := 0
+
+			for _, key := range nsKeysDefault {
+				if utils.IsStringInSlice(key, keys[ns]) {
+					n++
+				}
+			}
+
+			coverage.Namespaces[ns] = getCoveragePercent(n, len(nsKeysDefault))
+
+			present += n
+			total += len(nsKeysDefault)
+		}
+
+		coverage.Percent = getCoveragePercent(present, total)
+
+		languages.Languages[i].Coverage = coverage
+
+		if coverage.Percent < opts.CoverageMinimum {
+			languages.Warnings = append(languages.Warnings, fmt.Sprintf("language '%s' has a translation coverage of %.2f%% which is below the minimum of %.2f%%", lang.Locale, coverage.Percent, opts.CoverageMinimum))
+		}
+	}
+
+	return nil
+}
+
+// getLanguagesStats returns the aggregate statistics of the provided languages. The coverage of the languages backed
+// by locale files is computed if it was not already computed when the languages were loaded, without modifying them.
+func getLanguagesStats(languages *Languages) (stats LanguagesStats, err error) {
+	stats = LanguagesStats{
+		Languages:   len(languages.Languages),
+		Namespaces:  len(languages.Namespaces),
+		Backed:      []string{},
+		Synthesized: []string{},
+		Coverage:    map[string]LanguageCoverage{},
+	}
+
+	computed := &Languages{
+		Defaults:   languages.Defaults,
+		Namespaces: languages.Namespaces,
+		Languages:  make([]Language, len(languages.Languages)),
+	}
+
+	copy(computed.Languages, languages.Languages)
+
+	for _, lang := range computed.Languages {
+		if len(lang.files) != 0 && lang.Coverage == nil {
+			if err = getLanguagesCoverage(computed, languagesOptions{}); err != nil {
+				return stats, err
+			}
+
+			break
+		}
+	}
+
+	for _, lang := range computed.Languages {
+		if len(lang.files) == 0 {
+			stats.Synthesized = append(stats.Synthesized, lang.Locale)
+
+			continue
+		}
+
+		stats.Backed = append(stats.Backed, lang.Locale)
+
+		if lang.Coverage != nil {
+			stats.Coverage[lang.Locale] = *lang.Coverage
+		}
+	}
+
+	return stats, nil
+}
+
+// getLanguagesKeysReport returns the message keys of each namespace of each of the provided languages backed by locale
+// files which are missing or extra relative to the default language. Nested messages are flattened to dot separated
+// keys. Namespaces which neither have missing or extra keys are omitted.
+func getLanguagesKeysReport(languages *Languages) (reports []LanguageKeysReport, err error) {
+	var keysDefault map[string][]string
+
+	if keysDefault, err = getLanguageKeys(languages.Default()); err != nil {
+		return nil, err
+	}
+
+	for _, lang := range languages.Languages {
+		if len(lang.files) == 0 || lang.Locale == languages.Defaults.Language.Locale {
+			continue
+		}
+
+		var keys map[string][]string
+
+		if keys, err = getLanguageKeys(lang); err != nil {
+			return nil, err
+		}
+
+		for _, ns := range languages.Namespaces {
+			report := LanguageKeysReport{
+				Locale:    lang.Locale,
+				Namespace: ns,
+			}
+
+			for _, key := range keysDefault[ns] {
+				if !utils.IsStringInSlice(key, keys[ns]) {
+					report.Missing = append(report.Missing, key)
+				}
+			}
+
+			for _, key := range keys[ns] {
+				if !utils.IsStringInSlice(key, keysDefault[ns]) {
+					report.Extra = append(report.Extra, key)
+				}
+			}
+
+			if len(report.Missing) != 0 || len(report.Extra) != 0 {
+				reports = append(reports, report)
+			}
+		}
+	}
+
+	return reports, nil
+}
+
+// getLanguageKeys returns the message keys of each namespace of a language.
+func getLanguageKeys(lang Language) (keys map[string][]string, err error) {
+	keys = map[string][]string{}
+
+	for ns, file := range lang.files {
+		var messages map[string]any
+
+		if messages, err = readLocaleFile(file.fsys, file.name); err != nil {
+			return nil, err
+		}
+
+		keys[ns] = getLocaleMessageKeys("", messages)
+	}
+
+	return keys, nil
+}
+
+// getLocaleMessageKeys returns the keys of the provided messages with the keys of nested messages flattened using a
+// period as the separator.
+func getLocaleMessageKeys(prefix string, messages map[string]any) (keys []string) {
+	for key, value := range messages {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			keys = append(keys, getLocaleMessageKeys(key, nested)...)
+
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func getCoveragePercent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return math.Round(float64(n)/float64(total)*10000) / 100
+}
+
+// scriptsRTL are the scripts which are written right-to-left.
+var scriptsRTL = []string{"Adlm", "Arab", "Hebr", "Mand", "Nkoo", "Rohg", "Samr", "Syrc", "Thaa"}
+
+// getLanguageDirection returns the text direction of a language with the provided tag. The script is either the
+// explicit script of the tag or the script most likely used by the language when none is specified.
+func getLanguageDirection(tag language.Tag) (direction string) {
+	if script, _ := tag.Script(); utils.IsStringInSlice(script.String(), scriptsRTL) {
+		return localeDirectionRTL
+	}
+
+	return localeDirectionLTR
+}
+
+// hideLanguages marks the languages below the minimum coverage and the synthesized parent languages as hidden unless
+// they're a fallback of a visible language, and excludes them unless hidden languages are included.
+func hideLanguages(languages *Languages, opts languagesOptions) {
+	for i, lang := range languages.Languages {
+		if lang.Locale == languages.Defaults.Language.Locale {
+			continue
 		}
 
-		fdir, _ := filepath.Split(path)
+		languages.Languages[i].Hidden = len(lang.files) == 0 || lang.Coverage.Percent < opts.CoverageMinimum
+	}
 
-		locale := filepath.Base(fdir)
+	var fallbacks []string
 
-		var localeReal string
+	for _, lang := range languages.Languages {
+		if !lang.Hidden {
+			fallbacks = append(fallbacks, lang.Fallbacks...)
+		}
+	}
 
-		parts := strings.SplitN(locale, "-", 2)
-		if len(parts) == 2 && strings.EqualFold(parts[0], parts[1]) {
-			localeReal = parts[0]
-		} else {
-			localeReal = locale
+	for i, lang := range languages.Languages {
+		if lang.Hidden && utils.IsStringInSlice(lang.Locale, fallbacks) {
+			languages.Languages[i].Hidden = false
 		}
+	}
 
-		if utils.IsStringInSlice(localeReal, locales) {
-			for i, l := range languages.Languages {
-				if l.Locale == localeReal {
-					if utils.IsStringInSlice(ns, languages.Languages[i].Namespaces) {
-						break
-					}
+	if opts.IncludeHidden {
+		return
+	}
+
+	langs := make([]Language, 0, len(languages.Languages))
+
+	for _, lang := range languages.Languages {
+		if !lang.Hidden {
+			langs = append(langs, lang)
+		}
+	}
 
-					languages.Languages[i].Namespaces = append(languages.Languages[i].Namespaces, ns)
-					break
+	languages.Languages = langs
+}
+
+// sortNamespaces sorts the provided namespaces alphabetically with the default namespace first.
+func sortNamespaces(namespaces []string, namespaceDefault string) {
+	sort.Slice(namespaces, func(i, j int) bool {
+		switch {
+		case namespaces[i] == namespaceDefault:
+			return namespaces[j] != namespaceDefault
+		case namespaces[j] == namespaceDefault:
+			return false
+		default:
+			return namespaces[i] < namespaces[j]
+		}
+	})
+}
+
+// pluralCategories are the CLDR plural categories in their canonical order.
+var pluralCategories = []struct {
+	form plural.Form
+	name string
+}{
+	{plural.Zero, "zero"},
+	{plural.One, "one"},
+	{plural.Two, "two"},
+	{plural.Few, "few"},
+	{plural.Many, "many"},
+	{plural.Other, "other"},
+}
+
+// getLanguagePlurals returns the CLDR cardinal plural categories used by a language with the provided tag. The
+// categories are determined by matching sample numbers against the plural rules using valid operands, which are the
+// integers from 0 to 199, multiples of the powers of ten up to a million, and decimals with one or two visible fraction
+// digits. They're left empty when no plural rules are available for the language.
+func getLanguagePlurals(tag language.Tag) (categories []string) {
+	base, _ := tag.Base()
+
+	if _, exact := language.CompactIndex(language.Make(base.String())); !exact {
+		return nil
+	}
+
+	forms := map[plural.Form]bool{}
+
+	integers := make([]int, 0, 230)
+
+	for i := 0; i < 200; i++ {
+		integers = append(integers, i)
+	}
+
+	for p := 1000; p <= 1000000; p *= 10 {
+		for m := 1; m <= 5; m++ {
+			integers = append(integers, m*p, m*p+1)
+		}
+	}
+
+	for _, i := range integers {
+		forms[plural.Cardinal.MatchPlural(tag, i, 0, 0, 0, 0)] = true
+	}
+
+	for i := 0; i < 120; i++ {
+		for v, n := 1, 10; v <= 2; v, n = v+1, n*10 {
+			for f := 0; f < n; f++ {
+				w, t := v, f
+
+				for w > 0 && t%10 == 0 {
+					w, t = w-1, t/10
 				}
+
+				forms[plural.Cardinal.MatchPlural(tag, i, v, w, f, t)] = true
+			}
+		}
+	}
+
+	for _, category := range pluralCategories {
+		if forms[category.form] {
+			categories = append(categories, category.name)
+		}
+	}
+
+	return categories
+}
+
+// getLanguageParents returns the parents of the provided tag ordered from the nearest to the furthest, excluding the
+// root. The extensions and private use subtags of a tag are not inherited by its parents, instead the tag without them
+// is the nearest parent so a locale such as en-US-u-ca-gregory falls back to en-US and then en.
+func getLanguageParents(tag language.Tag) (parents []language.Tag) {
+	b, s, r := tag.Raw()
+
+	if base, err := language.Compose(b, s, r, tag.Variants()); err == nil && base != tag && !base.IsRoot() {
+		parents = append(parents, base)
+
+		tag = base
+	}
+
+	for p := tag.Parent(); !p.IsRoot(); p = p.Parent() {
+		parents = append(parents, p)
+	}
+
+	return parents
+}
+
+// getLanguageFallbacks returns the locales a language with the provided tag falls back to, in order. This is the locale
+// of the language matching each tag in the parent chain of the tag followed by the default locale. The default language
+// only falls back to itself so a default locale with a parent such as zh-CN doesn't create a fallback cycle.
+func getLanguageFallbacks(languages *Languages, tag language.Tag) (fallbacks []string) {
+	if tag == languages.Defaults.Language.Tag {
+		return []string{languages.Defaults.Language.Locale}
+	}
+
+	for _, p := range getLanguageParents(tag) {
+		if p == languages.Defaults.Language.Tag {
+			break
+		}
+
+		if lang, ok := languages.lookup(p, false); ok {
+			fallbacks = append(fallbacks, lang.Locale)
+		}
+	}
+
+	return append(fallbacks, languages.Defaults.Language.Locale)
+}
+
+// getLocaleFileParts returns the locale, namespace, and extension of a locale file. Files in the root directory use the
+// flat layout where the locale and namespace are both part of the file name i.e. en-US.portal.json, and all other
+// files use the nested layout where the locale is the name of the parent directory i.e. en-US/portal.json.
+func getLocaleFileParts(name string) (locale, ns, ext string) {
+	base := path.Base(name)
+
+	ext = strings.ToLower(path.Ext(base))
+	stem := strings.TrimSuffix(base, path.Ext(base))
+
+	if dir := path.Dir(name); dir != "." {
+		return path.Base(dir), strings.ToLower(stem), ext
+	}
+
+	if i := strings.Index(stem, "."); i != -1 {
+		return stem[:i], strings.ToLower(stem[i+1:]), ext
+	}
+
+	return ".", strings.ToLower(stem), ext
+}
+
+// discoverLanguages walks the provided fs.FS and adds the locales and namespaces it finds to the provided Languages.
+//
+//nolint:gocyclo
+func discoverLanguages(fsys fs.FS, opts languagesOptions, languages *Languages) (err error) {
+	seen := map[string]string{}
+
+	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, errWalk error) (err error) {
+		if errWalk != nil {
+			return errWalk
+		}
+
+		if entry.IsDir() {
+			if name != "." && isLocaleNameIgnored(entry.Name(), opts.Ignore) {
+				return fs.SkipDir
 			}
 
 			return nil
 		}
 
+		locale, ns, ext := getLocaleFileParts(name)
+
+		if isLocaleFileSkipped(name, locale, opts.Ignore) {
+			return nil
+		}
+
+		if !utils.IsStringInSlice(ext, localeExtensions) {
+			if opts.Validate && path.Dir(name) != "." {
+				languages.Warnings = append(languages.Warnings, fmt.Sprintf("file '%s' is not a recognized locale file", name))
+			}
+
+			return nil
+		}
+
+		localeReal := getLocaleReal(locale)
+
 		var tag language.Tag
 
 		if tag, err = language.Parse(localeReal); err != nil {
-			return fmt.Errorf("failed to parse language '%s': %w", localeReal, err)
+			if path.Dir(name) == "." {
+				if opts.Validate {
+					languages.Warnings = append(languages.Warnings, fmt.Sprintf("file '%s' has been skipped as its locale '%s' failed to parse: %v", name, locale, err))
+				}
+
+				return nil
+			}
+
+			if !opts.Lenient {
+				return fmt.Errorf("failed to parse language '%s': %w", localeReal, err)
+			}
+
+			if !utils.IsStringInSlice(locale, languages.Skipped) {
+				languages.Skipped = append(languages.Skipped, locale)
+
+				if opts.Failures.add(locale, err) {
+					languages.Warnings = append(languages.Warnings, fmt.Sprintf("locale '%s' has been skipped as it failed to parse: %v", locale, err))
+				}
+			}
+
+			return nil
+		}
+
+		if opts.Validate {
+			if _, err = readLocaleFile(fsys, name); err != nil {
+				languages.Warnings = append(languages.Warnings, err.Error())
+
+				return nil
+			}
+		}
+
+		if !utils.IsStringInSlice(ns, languages.Namespaces) {
+			languages.Namespaces = append(languages.Namespaces, ns)
+		}
+
+		key := tag.String() + "/" + ns
+
+		if existing, ok := seen[key]; ok {
+			languages.Warnings = append(languages.Warnings, fmt.Sprintf("file '%s' is a duplicate of file '%s' for the locale '%s' and namespace '%s'", name, existing, localeReal, ns))
+
+			return nil
+		}
+
+		seen[key] = name
+
+		file := languageFile{fsys: fsys, name: name}
+
+		for i, l := range languages.Languages {
+			if l.Tag.String() != tag.String() {
+				continue
+			}
+
+			if l.spelling != locale {
+				languages.Warnings = append(languages.Warnings, fmt.Sprintf("file '%s' has the locale '%s' which resolves to the same language as the locale '%s' so they have been merged as the locale '%s'", name, locale, l.spelling, tag.String()))
+
+				languages.Languages[i].Locale = tag.String()
+			}
+
+			languages.Languages[i].files[ns] = file
+
+			if !utils.IsStringInSlice(ns, languages.Languages[i].Namespaces) {
+				languages.Languages[i].Namespaces = append(languages.Languages[i].Namespaces, ns)
+			}
+
+			return nil
 		}
 
 		l := Language{
 			Display:    display.English.Tags().Name(tag),
 			Locale:     localeReal,
+			Canonical:  tag.String(),
+			Direction:  getLanguageDirection(tag),
 			Namespaces: []string{ns},
 			Fallbacks:  []string{languages.Defaults.Language.Locale},
+			Plurals:    getLanguagePlurals(tag),
 			Tag:        tag,
+
+			files:    map[string]languageFile{ns: file},
+			spelling: locale,
 		}
 
 		languages.Languages = append(languages.Languages, l)
 
-		locales = append(locales, l.Locale)
-
 		return nil
-	}); err != nil {
-		return nil, err
+	})
+}
+
+// containsLanguage returns true if the provided fs.FS contains a locale file for the provided locale, or for its alias
+// when there is none for the locale itself. The locale is normalized the same way as the discovered locales. Unlike
+// loading the languages the walk stops at the first locale file for the locale and the locale files are not read.
+// Synthesized parent languages are not considered and the locales which can't be parsed are skipped.
+func containsLanguage(fsys fs.FS, opts languagesOptions, locale string) (found bool, err error) {
+	var foundAlias bool
+
+	var tag language.Tag
+
+	if tag, err = language.Parse(getLocaleReal(locale)); err != nil {
+		return false, fmt.Errorf("failed to parse language '%s': %w", locale, err)
 	}
 
-	var langs []Language //nolint:prealloc
+	aliased, isAliased := (&Languages{Aliases: getLocaleAliases(opts)}).alias(tag)
 
-	for i, lang := range languages.Languages {
-		p := lang.Tag.Parent()
+	if opts.Ignore == nil {
+		opts.Ignore = localeIgnoreDefault
+	}
 
-		if p.String() == "und" || strings.Contains(p.String(), "-") {
-			continue
+	err = fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, errWalk error) error {
+		if errWalk != nil {
+			return errWalk
 		}
 
-		if utils.IsStringInSlice(p.String(), locales) {
-			continue
+		if entry.IsDir() {
+			if name != "." && isLocaleNameIgnored(entry.Name(), opts.Ignore) {
+				return fs.SkipDir
+			}
+
+			return nil
 		}
 
-		if p.String() != lang.Locale {
-			lang.Fallbacks = append([]string{p.String()}, lang.Fallbacks...)
+		l, _, ext := getLocaleFileParts(name)
+
+		if isLocaleFileSkipped(name, l, opts.Ignore) || !utils.IsStringInSlice(ext, localeExtensions) {
+			return nil
 		}
 
-		languages.Languages[i] = lang
+		t, errParse := language.Parse(getLocaleReal(l))
 
-		l := Language{
-			Display:    display.English.Tags().Name(p),
-			Locale:     p.String(),
-			Namespaces: lang.Namespaces,
-			Fallbacks:  []string{languages.Defaults.Language.Locale},
-			Tag:        p,
+		switch {
+		case errParse != nil:
+			return nil
+		case t == tag:
+			found = true
+
+			return fs.SkipAll
+		case isAliased && t == aliased:
+			foundAlias = true
 		}
 
-		langs = append(langs, l)
+		return nil
+	})
+
+	return found || foundAlias, err
+}
 
-		locales = append(locales, l.Locale)
+// getLocaleAliases returns the built-in locale aliases with the aliases from the provided options added to them.
+func getLocaleAliases(opts languagesOptions) (aliases map[string]string) {
+	aliases = map[string]string{}
+
+	for locale, alias := range localeAliases {
+		aliases[locale] = alias
 	}
 
-	languages.Languages = append(languages.Languages, langs...)
+	for locale, alias := range opts.Aliases {
+		aliases[locale] = alias
+	}
 
-	sort.Slice(languages.Languages, func(i, j int) bool {
-		return languages.Languages[i].Locale == localeDefault || languages.Languages[i].Locale < languages.Languages[j].Locale
-	})
+	return aliases
+}
 
-	return languages, nil
+// getLocaleReal returns the locale of a locale directory or file name, where a locale which has a region which is the
+// same as its language such as de-DE is the language alone.
+func getLocaleReal(locale string) string {
+	parts := strings.SplitN(locale, "-", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], parts[1]) {
+		return parts[0]
+	}
+
+	return locale
+}
+
+// localeFailures is the locales which have failed to parse across loads of the languages.
+type localeFailures struct {
+	mu      sync.Mutex
+	locales map[string]string
+}
+
+func newLocaleFailures() *localeFailures {
+	return &localeFailures{locales: map[string]string{}}
+}
+
+// add records the failure to parse the provided locale and returns true if it has not already been recorded with the
+// same error. A nil *localeFailures records nothing and always returns true.
+func (f *localeFailures) add(locale string, err error) bool {
+	if f == nil {
+		return true
+	}
+
+	f.mu.Lock()
+
+	defer f.mu.Unlock()
+
+	if existing, ok := f.locales[locale]; ok && existing == err.Error() {
+		return false
+	}
+
+	f.locales[locale] = err.Error()
+
+	return true
+}
+
+// isLocaleFileSkipped returns true if the file with the provided name and locale is a file in the root directory which
+// isn't a locale file using the flat layout, i.e. its name is ignored or it has no locale.
+func isLocaleFileSkipped(name, locale string, ignore []string) bool {
+	if path.Dir(name) != "." {
+		return false
+	}
+
+	return locale == "." || locale == "" || isLocaleNameIgnored(path.Base(name), ignore)
+}
+
+// isLocaleNameIgnored returns true if the provided directory or file name matches any of the provided patterns.
+func isLocaleNameIgnored(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readLocaleFile reads and decodes the messages from a locale file.
+func readLocaleFile(fsys fs.FS, name string) (messages map[string]any, err error) {
+	var data []byte
+
+	if data, err = fs.ReadFile(fsys, name); err != nil {
+		return nil, fmt.Errorf("failed to read locale file '%s': %w", name, err)
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case extYAML, extYML:
+		err = yaml.Unmarshal(data, &messages)
+	default:
+		err = json.Unmarshal(data, &messages)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse locale file '%s': %w", name, err)
+	}
+
+	return messages, nil
 }
@@ -0,0 +1,1254 @@
+package main
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestGetLanguagesFromFS(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     fstest.MapFS
+		expected []string
+		err      string
+	}{
+		{
+			"ShouldDiscoverLanguages",
+			fstest.MapFS{
+				"en/portal.json":    {Data: []byte("{}")},
+				"en/settings.json":  {Data: []byte("{}")},
+				"pt-BR/portal.json": {Data: []byte("{}")},
+			},
+			[]string{"en", "pt", "pt-BR"},
+			"",
+		},
+		{
+			"ShouldIgnoreNonJSONFiles",
+			fstest.MapFS{
+				"en/portal.json": {Data: []byte("{}")},
+				"en/README.md":   {Data: []byte("# Example")},
+			},
+			[]string{"en"},
+			"",
+		},
+		{
+			"ShouldDiscoverYAMLLanguages",
+			fstest.MapFS{
+				"en/portal.json":    {Data: []byte("{}")},
+				"de/portal.yaml":    {Data: []byte("key: value")},
+				"pt-BR/portal.yml":  {Data: []byte("key: value")},
+				"pt-BR/settings.md": {Data: []byte("# Example")},
+			},
+			[]string{"en", "de", "pt", "pt-BR"},
+			"",
+		},
+		{
+			"ShouldFailInvalidLocale",
+			fstest.MapFS{
+				"en/portal.json":        {Data: []byte("{}")},
+				"abcdefghi/portal.json": {Data: []byte("{}")},
+			},
+			nil,
+			"failed to parse language 'abcdefghi': language: tag is not well-formed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages, err := getLanguagesFromFS(tc.have, languagesOptions{})
+
+			if tc.err == "" {
+				require.NoError(t, err)
+
+				locales := make([]string, len(languages.Languages))
+
+				for i, l := range languages.Languages {
+					locales[i] = l.Locale
+				}
+
+				assert.ElementsMatch(t, tc.expected, locales)
+			} else {
+				assert.EqualError(t, err, tc.err)
+				assert.Nil(t, languages)
+			}
+		})
+	}
+}
+
+func TestGetLanguagesFromFSShouldDeriveNamespaceFromExtensions(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":  {Data: []byte("{}")},
+		"en/settings.yml": {Data: []byte("key: value")},
+		"en/consent.yaml": {Data: []byte("key: value")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"portal", "settings", "consent"}, languages.Namespaces)
+}
+
+func TestGetLanguagesFromFSValidate(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"key":"value"}`)},
+		"en/settings.json":  {Data: []byte(`{"key":`)},
+		"en/README.md":      {Data: []byte("# Example")},
+		"de/portal.yml":     {Data: []byte("key: [value")},
+		"fr/portal.yaml":    {Data: []byte("key: value")},
+		"fr/settings.json":  {Data: []byte(`{"key":"value"}`)},
+		"docs.md":           {Data: []byte("# Example")},
+		"pt-BR/portal.json": {Data: []byte(`{"key":"value"}`)},
+	}
+
+	languages, err := getLanguagesFromFS(have, languagesOptions{})
+
+	require.NoError(t, err)
+	assert.Empty(t, languages.Warnings)
+
+	languages, err = getLanguagesFromFS(have, languagesOptions{Validate: true})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"failed to parse locale file 'de/portal.yml': yaml: line 1: did not find expected ',' or ']'",
+		"file 'en/README.md' is not a recognized locale file",
+		"failed to parse locale file 'en/settings.json': unexpected end of JSON input",
+	}, languages.Warnings)
+
+	have["abcdefghi/portal.json"] = &fstest.MapFile{Data: []byte(`{"key":`)}
+
+	_, err = getLanguagesFromFS(have, languagesOptions{Validate: true})
+
+	assert.EqualError(t, err, "failed to parse language 'abcdefghi': language: tag is not well-formed")
+
+	delete(have, "abcdefghi/portal.json")
+
+	have["en/settings.json"] = &fstest.MapFile{Data: []byte(`{"key":"value"}`)}
+
+	languages, err = getLanguagesFromFS(have, languagesOptions{Validate: true})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"failed to parse locale file 'de/portal.yml': yaml: line 1: did not find expected ',' or ']'",
+		"file 'en/README.md' is not a recognized locale file",
+		"language 'pt-BR' is missing the namespaces 'settings' which are present in the default language",
+	}, languages.Warnings)
+
+	locales := make([]string, len(languages.Languages))
+
+	for i, l := range languages.Languages {
+		locales[i] = l.Locale
+	}
+
+	assert.ElementsMatch(t, []string{"en", "fr", "pt", "pt-BR"}, locales)
+}
+
+func TestGetLanguagesFromFSes(t *testing.T) {
+	base := fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"key":"base"}`)},
+		"en/settings.json":  {Data: []byte(`{"key":"base"}`)},
+		"de-DE/portal.json": {Data: []byte(`{"key":"base"}`)},
+	}
+
+	override := fstest.MapFS{
+		"en/portal.json":     {Data: []byte(`{"key":"override"}`)},
+		"de-DE/consent.json": {Data: []byte(`{"key":"override"}`)},
+		"pt-BR/portal.json":  {Data: []byte(`{"key":"override"}`)},
+	}
+
+	languages, err := getLanguagesFromFSes([]fs.FS{base, override}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"portal", "consent", "settings"}, languages.Namespaces)
+
+	locales := make([]string, len(languages.Languages))
+
+	for i, l := range languages.Languages {
+		locales[i] = l.Locale
+
+		switch l.Locale {
+		case "en":
+			assert.Equal(t, []string{"portal", "settings"}, l.Namespaces)
+			assert.Equal(t, languageFile{fsys: override, name: "en/portal.json"}, l.files["portal"])
+			assert.Equal(t, languageFile{fsys: base, name: "en/settings.json"}, l.files["settings"])
+		case "de":
+			assert.Equal(t, []string{"portal", "consent"}, l.Namespaces)
+			assert.Equal(t, languageFile{fsys: base, name: "de-DE/portal.json"}, l.files["portal"])
+			assert.Equal(t, languageFile{fsys: override, name: "de-DE/consent.json"}, l.files["consent"])
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"en", "de", "pt", "pt-BR"}, locales)
+}
+
+func TestGetLanguagesFromFSShouldSortDefaultFirst(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"zh-CN/portal.json": {Data: []byte("{}")},
+		"ar/portal.json":    {Data: []byte("{}")},
+		"fr/portal.json":    {Data: []byte("{}")},
+		"en/portal.json":    {Data: []byte("{}")},
+		"de/portal.json":    {Data: []byte("{}")},
+		"es/portal.json":    {Data: []byte("{}")},
+		"cs-CZ/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	locales := make([]string, len(languages.Languages))
+
+	for i, l := range languages.Languages {
+		locales[i] = l.Locale
+	}
+
+	assert.Equal(t, []string{"en", "ar", "cs", "cs-CZ", "de", "es", "fr", "zh", "zh-CN"}, locales)
+}
+
+func TestGetLanguagesFromFSShouldComputeParentFallbacks(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":         {Data: []byte("{}")},
+		"zh-Hant-TW/portal.json": {Data: []byte("{}")},
+		"zh-Hans/portal.json":    {Data: []byte("{}")},
+		"pt/portal.json":         {Data: []byte("{}")},
+		"pt-BR/portal.json":      {Data: []byte("{}")},
+		"en-GB/portal.json":      {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	fallbacks := map[string][]string{}
+
+	for _, l := range languages.Languages {
+		fallbacks[l.Locale] = l.Fallbacks
+	}
+
+	assert.Equal(t, map[string][]string{
+		"en":         {"en"},
+		"en-001":     {"en"},
+		"en-GB":      {"en-001", "en"},
+		"pt":         {"en"},
+		"pt-BR":      {"pt", "en"},
+		"zh":         {"en"},
+		"zh-Hans":    {"zh", "en"},
+		"zh-Hant":    {"en"},
+		"zh-Hant-TW": {"zh-Hant", "en"},
+	}, fallbacks)
+}
+
+func TestGetLanguagesFromFSShouldIgnoreDirectories(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":            {Data: []byte("{}")},
+		"de/portal.json":            {Data: []byte("{}")},
+		".git/HEAD":                 {Data: []byte("ref: refs/heads/master")},
+		".git/objects/info.json":    {Data: []byte("{}")},
+		"_source/en/portal.json":    {Data: []byte("{}")},
+		"_source/not-a-locale.json": {Data: []byte("{}")},
+		"abcdefghi/portal.json":     {Data: []byte("{}")},
+		"package.json":              {Data: []byte("{}")},
+		"README.md":                 {Data: []byte("# Locales")},
+		".prettierrc.json":          {Data: []byte("{}")},
+		"tsconfig.app.json":         {Data: []byte("{}")},
+		"fr.portal.json":            {Data: []byte("{}")},
+	}
+
+	testCases := []struct {
+		name     string
+		opts     languagesOptions
+		expected []string
+		warnings []string
+		err      string
+	}{
+		{
+			"ShouldIgnoreDefault",
+			languagesOptions{Validate: true, Ignore: nil},
+			nil,
+			nil,
+			"failed to parse language 'abcdefghi': language: tag is not well-formed",
+		},
+		{
+			"ShouldIgnoreCustom",
+			languagesOptions{Validate: true, Ignore: []string{".*", "_*", "abcdefghi", "fr.*.json", "tsconfig.*"}},
+			[]string{"en", "de"},
+			nil,
+			"",
+		},
+		{
+			"ShouldSkipRootFilesWhichAreNotLocaleFiles",
+			languagesOptions{Validate: true, Ignore: []string{".git", "_*", "abcdefghi", "fr.*.json"}},
+			[]string{"en", "de"},
+			[]string{"file 'tsconfig.app.json' has been skipped as its locale 'tsconfig' failed to parse: language: tag is not well-formed"},
+			"",
+		},
+		{
+			"ShouldNotIgnoreRootLocaleFiles",
+			languagesOptions{Validate: true, Ignore: []string{".*", "_*", "abcdefghi"}},
+			[]string{"en", "de", "fr"},
+			[]string{"file 'tsconfig.app.json' has been skipped as its locale 'tsconfig' failed to parse: language: tag is not well-formed"},
+			"",
+		},
+		{
+			"ShouldNotReportRootFilesWithoutValidate",
+			languagesOptions{Ignore: []string{".*", "_*", "abcdefghi"}},
+			[]string{"en", "de", "fr"},
+			nil,
+			"",
+		},
+		{
+			"ShouldIgnoreNone",
+			languagesOptions{Validate: true, Ignore: []string{}},
+			nil,
+			nil,
+			"failed to parse language 'objects': language: tag is not well-formed",
+		},
+		{
+			"ShouldErrorBadPattern",
+			languagesOptions{Ignore: []string{"["}},
+			nil,
+			nil,
+			"failed to parse ignore pattern '[': syntax error in pattern",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages, err := getLanguagesFromFS(have, tc.opts)
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.warnings, languages.Warnings)
+
+			var locales []string
+
+			for _, l := range languages.Languages {
+				locales = append(locales, l.Locale)
+			}
+
+			assert.Equal(t, tc.expected, locales)
+		})
+	}
+}
+
+func TestContainsLanguage(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":         {Data: []byte("{}")},
+		"de-DE/portal.json":      {Data: []byte("{}")},
+		"pt-BR/portal.json":      {Data: []byte("{}")},
+		"fr.portal.json":         {Data: []byte("{}")},
+		"abcdefghi/portal.json":  {Data: []byte("{}")},
+		"_source/ja/portal.json": {Data: []byte("{}")},
+		"ko/README.md":           {Data: []byte("# Korean")},
+		"nb/portal.json":         {Data: []byte("{}")},
+		"sr-Latn/portal.json":    {Data: []byte("{}")},
+		"package.json":           {Data: []byte("{}")},
+	}
+
+	testCases := []struct {
+		name     string
+		have     string
+		expected bool
+		err      string
+	}{
+		{"ShouldContainLanguage", "en", true, ""},
+		{"ShouldContainRegion", "pt-br", true, ""},
+		{"ShouldContainSameRegion", "de", true, ""},
+		{"ShouldContainSameRegionAsDirectory", "de-DE", true, ""},
+		{"ShouldContainSameRegionCase", "de-de", true, ""},
+		{"ShouldContainAlias", "no", true, ""},
+		{"ShouldContainAliasRegion", "no-NO", true, ""},
+		{"ShouldContainCustomAlias", "sh", true, ""},
+		{"ShouldContainFlat", "fr", true, ""},
+		{"ShouldNotContainSynthesizedParent", "pt", false, ""},
+		{"ShouldNotContainIgnored", "ja", false, ""},
+		{"ShouldNotContainUnrecognized", "ko", false, ""},
+		{"ShouldNotContainUnavailable", "es", false, ""},
+		{"ShouldErrorMalformed", "abcdefghi", false, "failed to parse language 'abcdefghi': language: tag is not well-formed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := containsLanguage(have, languagesOptions{Aliases: map[string]string{"sh": "sr-Latn"}}, tc.have)
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetLanguagesFromFSLenient(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":          {Data: []byte("{}")},
+		"de/portal.json":          {Data: []byte("{}")},
+		"abcdefghi/portal.json":   {Data: []byte("{}")},
+		"abcdefghi/settings.json": {Data: []byte("{}")},
+		"zyxwvutsr/consent.json":  {Data: []byte("{}")},
+	}
+
+	_, err := getLanguagesFromFS(have, languagesOptions{})
+
+	assert.EqualError(t, err, "failed to parse language 'abcdefghi': language: tag is not well-formed")
+
+	failures := newLocaleFailures()
+
+	warnings := []string{
+		"locale 'abcdefghi' has been skipped as it failed to parse: language: tag is not well-formed",
+		"locale 'zyxwvutsr' has been skipped as it failed to parse: language: tag is not well-formed",
+	}
+
+	for i := 0; i < 2; i++ {
+		languages, err := getLanguagesFromFS(have, languagesOptions{Lenient: true, Failures: failures})
+
+		require.NoError(t, err)
+
+		var locales []string
+
+		for _, l := range languages.Languages {
+			locales = append(locales, l.Locale)
+		}
+
+		assert.Equal(t, []string{"en", "de"}, locales)
+		assert.Equal(t, []string{"portal"}, languages.Namespaces)
+		assert.Equal(t, []string{"abcdefghi", "zyxwvutsr"}, languages.Skipped)
+
+		if i == 0 {
+			assert.Equal(t, warnings, languages.Warnings)
+		} else {
+			assert.Empty(t, languages.Warnings)
+		}
+	}
+
+	languages, err := getLanguagesFromFS(have, languagesOptions{Lenient: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, warnings, languages.Warnings)
+}
+
+func TestLocaleAliases(t *testing.T) {
+	for locale, alias := range localeAliases {
+		t.Run(locale, func(t *testing.T) {
+			tag, err := language.Parse(locale)
+
+			require.NoError(t, err)
+			assert.Equal(t, locale, tag.String(), "the locale is replaced when parsed so the alias is never used")
+
+			_, err = language.Parse(alias)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGetLanguagesFromFSShouldComputeSynthesizedParentFallbacks(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":                  {Data: []byte("{}")},
+		"es-MX/portal.json":               {Data: []byte("{}")},
+		"zh-CN/portal.json":               {Data: []byte("{}")},
+		"de-CH-1996-x-formal/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	synthesized := map[string][]string{}
+
+	for _, l := range languages.Languages {
+		if len(l.files) == 0 {
+			synthesized[l.Locale] = l.Fallbacks
+		}
+	}
+
+	assert.Equal(t, map[string][]string{
+		"es":         {"en"},
+		"es-419":     {"es", "en"},
+		"zh":         {"en"},
+		"de":         {"en"},
+		"de-CH":      {"de", "en"},
+		"de-CH-1996": {"de-CH", "de", "en"},
+	}, synthesized)
+
+	for _, l := range languages.Languages {
+		if len(l.Fallbacks) < 2 {
+			continue
+		}
+
+		parent, ok := languages.ByLocale(l.Fallbacks[0])
+
+		require.True(t, ok)
+		assert.Equal(t, l.Fallbacks[1:], parent.Fallbacks, "the fallbacks of '%s' should continue with the fallbacks of its parent '%s'", l.Locale, parent.Locale)
+	}
+}
+
+func TestGetLanguagesFromFSShouldMatchParentFallbacksByTag(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":                 {Data: []byte("{}")},
+		"en-us/portal.json":              {Data: []byte("{}")},
+		"en-US-u-ca-gregory/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	fallbacks := map[string][]string{}
+
+	for _, l := range languages.Languages {
+		fallbacks[l.Locale] = l.Fallbacks
+	}
+
+	assert.Equal(t, map[string][]string{
+		"en":                 {"en"},
+		"en-us":              {"en"},
+		"en-US-u-ca-gregory": {"en-us", "en"},
+	}, fallbacks)
+}
+
+func TestGetLanguagesFromFSShouldNotCycleDefaultFallbacks(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"zh-CN/portal.json": {Data: []byte("{}")},
+		"zh-TW/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{DefaultLocale: "zh-CN"})
+
+	require.NoError(t, err)
+
+	fallbacks := map[string][]string{}
+
+	for _, l := range languages.Languages {
+		fallbacks[l.Locale] = l.Fallbacks
+	}
+
+	assert.Equal(t, map[string][]string{
+		"zh-CN":   {"zh-CN"},
+		"en":      {"zh-CN"},
+		"zh":      {"zh-CN"},
+		"zh-Hant": {"zh-CN"},
+		"zh-TW":   {"zh-Hant", "zh-CN"},
+	}, fallbacks)
+}
+
+func TestGetLanguagesFromFSShouldSupportExtensions(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":                  {Data: []byte("{}")},
+		"en-US-u-ca-gregory/portal.json":  {Data: []byte("{}")},
+		"de-x-formal/portal.json":         {Data: []byte("{}")},
+		"de-CH-1996-x-formal/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{Validate: true})
+
+	require.NoError(t, err)
+	assert.Empty(t, languages.Warnings)
+
+	fallbacks := map[string][]string{}
+	canonical := map[string]string{}
+
+	for _, l := range languages.Languages {
+		fallbacks[l.Locale] = l.Fallbacks
+		canonical[l.Locale] = l.Canonical
+	}
+
+	assert.Equal(t, map[string][]string{
+		"en":                  {"en"},
+		"en-US":               {"en"},
+		"en-US-u-ca-gregory":  {"en-US", "en"},
+		"de":                  {"en"},
+		"de-CH":               {"de", "en"},
+		"de-CH-1996":          {"de-CH", "de", "en"},
+		"de-CH-1996-x-formal": {"de-CH-1996", "de-CH", "de", "en"},
+		"de-x-formal":         {"de", "en"},
+	}, fallbacks)
+
+	assert.Equal(t, "en-US-u-ca-gregory", canonical["en-US-u-ca-gregory"])
+	assert.Equal(t, "de-x-formal", canonical["de-x-formal"])
+}
+
+func TestGetLanguageParents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected []string
+	}{
+		{"ShouldReturnNoneForLanguage", "en", nil},
+		{"ShouldReturnLanguageForRegion", "en-GB", []string{"en-001", "en"}},
+		{"ShouldReturnBaseForExtension", "en-US-u-ca-gregory", []string{"en-US", "en"}},
+		{"ShouldReturnBaseForPrivateUse", "de-x-formal", []string{"de"}},
+		{"ShouldReturnBaseForVariant", "de-CH-1996-x-formal", []string{"de-CH-1996", "de-CH", "de"}},
+		{"ShouldReturnNoneForPrivateUseOnly", "x-formal", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+
+			for _, p := range getLanguageParents(language.MustParse(tc.have)) {
+				actual = append(actual, p.String())
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetLanguageDirection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+	}{
+		{"ShouldDetectEnglish", "en", localeDirectionLTR},
+		{"ShouldDetectArabic", "ar", localeDirectionRTL},
+		{"ShouldDetectArabicRegion", "ar-SA", localeDirectionRTL},
+		{"ShouldDetectHebrew", "he", localeDirectionRTL},
+		{"ShouldDetectPersian", "fa", localeDirectionRTL},
+		{"ShouldDetectChineseTraditional", "zh-Hant-TW", localeDirectionLTR},
+		{"ShouldDetectExplicitScriptLatin", "az-Latn", localeDirectionLTR},
+		{"ShouldDetectExplicitScriptArabic", "az-Arab", localeDirectionRTL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getLanguageDirection(language.MustParse(tc.have)))
+		})
+	}
+}
+
+func TestGetLanguagesFromFSCoverage(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":{"d":"d","e":"e"}}`)},
+		"en/settings.json":  {Data: []byte(`{"a":"a","b":"b","c":"c","d":"d"}`)},
+		"de/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":{"d":"d","e":"e"}}`)},
+		"de/settings.json":  {Data: []byte(`{"a":"a","b":"b","c":"c","d":"d"}`)},
+		"fr/portal.json":    {Data: []byte(`{"a":"a","c":{"d":"d"},"x":"x"}`)},
+		"pt-BR/portal.yaml": {Data: []byte("a: a\nc:\n  e: e\n")},
+	}
+
+	languages, err := getLanguagesFromFS(have, languagesOptions{})
+
+	require.NoError(t, err)
+
+	for _, l := range languages.Languages {
+		assert.Nil(t, l.Coverage)
+	}
+
+	languages, err = getLanguagesFromFS(have, languagesOptions{Coverage: true, CoverageMinimum: 50, IncludeHidden: true})
+
+	require.NoError(t, err)
+
+	coverage := map[string]*LanguageCoverage{}
+
+	for _, l := range languages.Languages {
+		coverage[l.Locale] = l.Coverage
+	}
+
+	assert.Equal(t, map[string]*LanguageCoverage{
+		"en":    {Percent: 100, Namespaces: map[string]float64{"portal": 100, "settings": 100}},
+		"de":    {Percent: 100, Namespaces: map[string]float64{"portal": 100, "settings": 100}},
+		"fr":    {Percent: 25, Namespaces: map[string]float64{"portal": 50, "settings": 0}},
+		"pt":    nil,
+		"pt-BR": {Percent: 25, Namespaces: map[string]float64{"portal": 50, "settings": 0}},
+	}, coverage)
+
+	assert.Equal(t, []string{
+		"language 'fr' has a translation coverage of 25.00% which is below the minimum of 50.00%",
+		"language 'pt-BR' has a translation coverage of 25.00% which is below the minimum of 50.00%",
+	}, languages.Warnings)
+}
+
+func TestGetLanguagesFromFSCoverageMinimum(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":"c","d":"d"}`)},
+		"de/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":"c","d":"d"}`)},
+		"fr/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":"c"}`)},
+		"pt-BR/portal.json": {Data: []byte(`{"a":"a"}`)},
+	}
+
+	testCases := []struct {
+		name     string
+		opts     languagesOptions
+		expected map[string]bool
+	}{
+		{
+			"ShouldNotHideWithoutMinimum",
+			languagesOptions{Coverage: true},
+			map[string]bool{"en": false, "de": false, "fr": false, "pt": false, "pt-BR": false},
+		},
+		{
+			"ShouldExcludeLanguagesBelowMinimum",
+			languagesOptions{Coverage: true, CoverageMinimum: 75},
+			map[string]bool{"en": false, "de": false, "fr": false},
+		},
+		{
+			"ShouldIncludeHiddenLanguages",
+			languagesOptions{Coverage: true, CoverageMinimum: 80, IncludeHidden: true},
+			map[string]bool{"en": false, "de": false, "fr": true, "pt": true, "pt-BR": true},
+		},
+		{
+			"ShouldNeverHideDefaultLanguage",
+			languagesOptions{Coverage: true, CoverageMinimum: 101},
+			map[string]bool{"en": false},
+		},
+		{
+			"ShouldNotHideWithoutCoverage",
+			languagesOptions{CoverageMinimum: 75},
+			map[string]bool{"en": false, "de": false, "fr": false, "pt": false, "pt-BR": false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages, err := getLanguagesFromFS(have, tc.opts)
+
+			require.NoError(t, err)
+
+			hidden := map[string]bool{}
+
+			for _, l := range languages.Languages {
+				hidden[l.Locale] = l.Hidden
+			}
+
+			assert.Equal(t, tc.expected, hidden)
+		})
+	}
+}
+
+func TestGetLanguagesFromFSCoverageMinimumShouldNotHideFallbacks(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":"c","d":"d"}`)},
+		"fr/portal.json":    {Data: []byte(`{"a":"a","b":"b","c":"c"}`)},
+		"fr-CA/portal.json": {Data: []byte(`{"a":"a","b":"b","c":"c","d":"d"}`)},
+		"pt-BR/portal.json": {Data: []byte(`{"a":"a"}`)},
+	}, languagesOptions{Coverage: true, CoverageMinimum: 80, IncludeHidden: true})
+
+	require.NoError(t, err)
+
+	hidden := map[string]bool{}
+
+	for _, l := range languages.Languages {
+		hidden[l.Locale] = l.Hidden
+	}
+
+	assert.Equal(t, map[string]bool{"en": false, "fr": false, "fr-CA": false, "pt": true, "pt-BR": true}, hidden)
+}
+
+func TestGetLanguagesStats(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"a":"a","b":"b"}`)},
+		"en/settings.json":  {Data: []byte(`{"a":"a","b":"b"}`)},
+		"de/portal.json":    {Data: []byte(`{"a":"a","b":"b"}`)},
+		"pt-BR/portal.json": {Data: []byte(`{"a":"a"}`)},
+	}
+
+	expected := LanguagesStats{
+		Languages:   4,
+		Namespaces:  2,
+		Backed:      []string{"en", "de", "pt-BR"},
+		Synthesized: []string{"pt"},
+		Coverage: map[string]LanguageCoverage{
+			"en":    {Percent: 100, Namespaces: map[string]float64{"portal": 100, "settings": 100}},
+			"de":    {Percent: 50, Namespaces: map[string]float64{"portal": 100, "settings": 0}},
+			"pt-BR": {Percent: 25, Namespaces: map[string]float64{"portal": 50, "settings": 0}},
+		},
+	}
+
+	for _, opts := range []languagesOptions{{}, {Coverage: true}} {
+		languages, err := getLanguagesFromFS(have, opts)
+
+		require.NoError(t, err)
+
+		stats, err := getLanguagesStats(languages)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, stats)
+
+		for _, l := range languages.Languages {
+			if len(l.files) != 0 {
+				assert.Equal(t, opts.Coverage, l.Coverage != nil)
+			}
+		}
+	}
+}
+
+func TestGetLanguagesKeysReport(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":   {Data: []byte(`{"a":"a","b":{"c":{"d":{"e":"e","f":"f"}},"g":"g"}}`)},
+		"en/settings.json": {Data: []byte(`{"a":"a","b":"b"}`)},
+		"de/portal.json":   {Data: []byte(`{"a":"a","b":{"c":{"d":{"e":"e","f":"f"}},"g":"g"}}`)},
+		"de/settings.json": {Data: []byte(`{"a":"a","b":"b"}`)},
+		"fr/portal.json":   {Data: []byte(`{"a":"a","b":{"c":{"d":{"e":"e","x":"x"}}},"y":{"z":"z"}}`)},
+		"fr/consent.json":  {Data: []byte(`{"a":"a"}`)},
+		"pt-BR/portal.yml": {Data: []byte("b:\n  c:\n    d:\n      f: f\n")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	reports, err := getLanguagesKeysReport(languages)
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []LanguageKeysReport{
+		{Locale: "fr", Namespace: "portal", Missing: []string{"b.c.d.f", "b.g"}, Extra: []string{"b.c.d.x", "y.z"}},
+		{Locale: "fr", Namespace: "consent", Extra: []string{"a"}},
+		{Locale: "fr", Namespace: "settings", Missing: []string{"a", "b"}},
+		{Locale: "pt-BR", Namespace: "portal", Missing: []string{"a", "b.c.d.e", "b.g"}},
+		{Locale: "pt-BR", Namespace: "settings", Missing: []string{"a", "b"}},
+	}, reports)
+
+	languages, err = getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json": {Data: []byte(`{"a":"a"}`)},
+		"de/portal.json": {Data: []byte(`{"a":`)},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	_, err = getLanguagesKeysReport(languages)
+
+	assert.EqualError(t, err, "failed to parse locale file 'de/portal.json': unexpected end of JSON input")
+}
+
+func TestGetLocaleMessageKeys(t *testing.T) {
+	assert.Equal(t, []string{"a", "b.c", "b.d.e", "b.d.f", "g"}, getLocaleMessageKeys("", map[string]any{
+		"a": "a",
+		"b": map[string]any{
+			"c": "c",
+			"d": map[string]any{
+				"e": "e",
+				"f": "f",
+			},
+		},
+		"g": "g",
+	}))
+}
+
+func TestGetLanguagesFromFSShouldHonorConfiguredDefaults(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"fr/portal.json":    {Data: []byte("{}")},
+		"fr/consent.json":   {Data: []byte("{}")},
+		"de-AT/portal.json": {Data: []byte("{}")},
+		"fr-CA/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{DefaultLocale: "fr", DefaultNamespace: "consent"})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, "fr", languages.Defaults.Language.Locale)
+	assert.Equal(t, "French", languages.Defaults.Language.Display)
+	assert.Equal(t, "consent", languages.Defaults.Namespace)
+
+	locales := make([]string, len(languages.Languages))
+	fallbacks := map[string][]string{}
+
+	for i, l := range languages.Languages {
+		locales[i] = l.Locale
+		fallbacks[l.Locale] = l.Fallbacks
+	}
+
+	assert.Equal(t, []string{"fr", "de", "de-AT", "en", "fr-CA"}, locales)
+	assert.Equal(t, map[string][]string{
+		"fr":    {"fr"},
+		"de":    {"fr"},
+		"de-AT": {"de", "fr"},
+		"en":    {"fr"},
+		"fr-CA": {"fr"},
+	}, fallbacks)
+}
+
+func TestGetLanguagesFromFSShouldNormalizeDefaultLocale(t *testing.T) {
+	have := fstest.MapFS{
+		"en/portal.json":    {Data: []byte(`{"a":"a"}`)},
+		"de-DE/portal.json": {Data: []byte(`{"a":"a","b":"b"}`)},
+		"de-AT/portal.json": {Data: []byte(`{"a":"a"}`)},
+		"pt-BR/portal.json": {Data: []byte(`{"a":"a","b":"b"}`)},
+	}
+
+	testCases := []struct {
+		name      string
+		have      string
+		expected  string
+		fallbacks map[string][]string
+	}{
+		{
+			"ShouldNormalizeSameRegion",
+			"de-DE",
+			"de",
+			map[string][]string{"de": {"de"}, "de-AT": {"de"}, "en": {"de"}, "pt": {"de"}, "pt-BR": {"pt", "de"}},
+		},
+		{
+			"ShouldNormalizeCase",
+			"pt-br",
+			"pt-BR",
+			map[string][]string{"pt-BR": {"pt-BR"}, "de": {"pt-BR"}, "de-AT": {"de", "pt-BR"}, "en": {"pt-BR"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages, err := getLanguagesFromFS(have, languagesOptions{DefaultLocale: tc.have, Coverage: true})
+
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, languages.Defaults.Language.Locale)
+			assert.Equal(t, tc.expected, languages.Languages[0].Locale)
+
+			fallbacks := map[string][]string{}
+
+			for _, l := range languages.Languages {
+				fallbacks[l.Locale] = l.Fallbacks
+			}
+
+			assert.Equal(t, tc.fallbacks, fallbacks)
+
+			for _, l := range languages.Languages {
+				if l.Locale == "en" {
+					assert.Equal(t, float64(50), l.Coverage.Percent)
+				}
+			}
+		})
+	}
+}
+
+func TestGetLanguagesFromFSShouldFailMissingDefaultLocale(t *testing.T) {
+	_, err := getLanguagesFromFS(fstest.MapFS{
+		"de/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	assert.EqualError(t, err, "failed to find the locale files of the default language 'en'")
+
+	_, err = getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{DefaultLocale: "de-DE"})
+
+	assert.EqualError(t, err, "failed to find the locale files of the default language 'de-DE'")
+}
+
+func TestGetLanguagesFromFSShouldFailInvalidDefaultLocale(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{DefaultLocale: "abcdefghi"})
+
+	assert.EqualError(t, err, "failed to parse default language: language: tag is not well-formed")
+	assert.Nil(t, languages)
+}
+
+func TestGetLanguagesFromFSShouldOverrideDisplay(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"pt-BR/portal.json": {Data: []byte("{}")},
+		"fr/portal.json":    {Data: []byte("{}")},
+	}, languagesOptions{Display: map[string]string{"en": "English (Default)", "pt": "Português", "pt-BR": "Português (Brasil)"}})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, "English (Default)", languages.Defaults.Language.Display)
+
+	displays := map[string]string{}
+
+	for _, l := range languages.Languages {
+		displays[l.Locale] = l.Display
+	}
+
+	assert.Equal(t, map[string]string{
+		"en":    "English (Default)",
+		"fr":    "French",
+		"pt":    "Português",
+		"pt-BR": "Português (Brasil)",
+	}, displays)
+}
+
+func TestGetLocaleFileParts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		have      string
+		locale    string
+		namespace string
+		ext       string
+	}{
+		{"ShouldParseNested", "en-US/portal.json", "en-US", "portal", ".json"},
+		{"ShouldParseNestedUpperCase", "en-US/Portal.JSON", "en-US", "portal", ".json"},
+		{"ShouldParseNestedDeep", "locales/en-US/portal.yml", "en-US", "portal", ".yml"},
+		{"ShouldParseFlat", "en-US.portal.json", "en-US", "portal", ".json"},
+		{"ShouldParseFlatUpperCase", "en-US.Portal.JSON", "en-US", "portal", ".json"},
+		{"ShouldParseRootWithoutLocale", "portal.json", ".", "portal", ".json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			locale, namespace, ext := getLocaleFileParts(tc.have)
+
+			assert.Equal(t, tc.locale, locale)
+			assert.Equal(t, tc.namespace, namespace)
+			assert.Equal(t, tc.ext, ext)
+		})
+	}
+}
+
+func TestGetLanguagesFromFSLayouts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     fstest.MapFS
+		expected map[string][]string
+		warnings []string
+	}{
+		{
+			"ShouldDiscoverNested",
+			fstest.MapFS{
+				"en/portal.json":    {Data: []byte("{}")},
+				"en/settings.json":  {Data: []byte("{}")},
+				"pt-BR/portal.json": {Data: []byte("{}")},
+			},
+			map[string][]string{
+				"en":    {"portal", "settings"},
+				"pt":    {"portal"},
+				"pt-BR": {"portal"},
+			},
+			nil,
+		},
+		{
+			"ShouldDiscoverFlat",
+			fstest.MapFS{
+				"en.portal.json":    {Data: []byte("{}")},
+				"en.settings.json":  {Data: []byte("{}")},
+				"pt-BR.portal.json": {Data: []byte("{}")},
+			},
+			map[string][]string{
+				"en":    {"portal", "settings"},
+				"pt":    {"portal"},
+				"pt-BR": {"portal"},
+			},
+			nil,
+		},
+		{
+			"ShouldDiscoverMixed",
+			fstest.MapFS{
+				"en/portal.json":     {Data: []byte("{}")},
+				"en.settings.json":   {Data: []byte("{}")},
+				"pt-BR/portal.json":  {Data: []byte("{}")},
+				"pt-BR.consent.json": {Data: []byte("{}")},
+			},
+			map[string][]string{
+				"en":    {"portal", "settings"},
+				"pt":    {"portal", "consent"},
+				"pt-BR": {"portal", "consent"},
+			},
+			nil,
+		},
+		{
+			"ShouldWarnDuplicate",
+			fstest.MapFS{
+				"en/portal.json": {Data: []byte("{}")},
+				"en.portal.json": {Data: []byte("{}")},
+			},
+			map[string][]string{
+				"en": {"portal"},
+			},
+			[]string{"file 'en.portal.json' is a duplicate of file 'en/portal.json' for the locale 'en' and namespace 'portal'"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages, err := getLanguagesFromFS(tc.have, languagesOptions{})
+
+			require.NoError(t, err)
+
+			namespaces := map[string][]string{}
+
+			for _, l := range languages.Languages {
+				namespaces[l.Locale] = l.Namespaces
+			}
+
+			assert.Equal(t, tc.expected, namespaces)
+			assert.Equal(t, tc.warnings, languages.Warnings)
+		})
+	}
+}
+
+func TestGetLanguagesFromFSShouldSortNamespaces(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/settings.json": {Data: []byte("{}")},
+		"en/consent.json":  {Data: []byte("{}")},
+		"en/portal.json":   {Data: []byte("{}")},
+		"de/zebra.json":    {Data: []byte("{}")},
+		"de/portal.json":   {Data: []byte("{}")},
+		"de/consent.json":  {Data: []byte("{}")},
+		"fr/settings.json": {Data: []byte("{}")},
+		"fr/alpha.json":    {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"portal", "alpha", "consent", "settings", "zebra"}, languages.Namespaces)
+
+	namespaces := map[string][]string{}
+
+	for _, l := range languages.Languages {
+		namespaces[l.Locale] = l.Namespaces
+	}
+
+	assert.Equal(t, map[string][]string{
+		"en": {"portal", "consent", "settings"},
+		"de": {"portal", "consent", "zebra"},
+		"fr": {"alpha", "settings"},
+	}, namespaces)
+}
+
+func TestGetLanguagePlurals(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected []string
+	}{
+		{"ShouldReturnEnglish", "en", []string{"one", "other"}},
+		{"ShouldReturnJapanese", "ja", []string{"other"}},
+		{"ShouldReturnPolish", "pl-PL", []string{"one", "few", "many", "other"}},
+		{"ShouldReturnArabic", "ar", []string{"zero", "one", "two", "few", "many", "other"}},
+		{"ShouldReturnSlovenian", "sl", []string{"one", "two", "few", "other"}},
+		{"ShouldReturnBreton", "br", []string{"one", "two", "few", "many", "other"}},
+		{"ShouldReturnHebrew", "he", []string{"one", "two", "many", "other"}},
+		{"ShouldReturnLatvian", "lv", []string{"zero", "one", "other"}},
+		{"ShouldReturnNilUnknown", "tlh", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getLanguagePlurals(language.MustParse(tc.have)))
+		})
+	}
+}
+
+func TestGetLanguagesFromFSShouldMergeLocaleSpellings(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":      {Data: []byte("{}")},
+		"pt-BR/portal.json":   {Data: []byte("{}")},
+		"pt-br/settings.json": {Data: []byte("{}")},
+		"PT-BR/consent.json":  {Data: []byte("{}")},
+		"de-DE/portal.json":   {Data: []byte("{}")},
+		"de/settings.json":    {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	namespaces := map[string][]string{}
+
+	for _, l := range languages.Languages {
+		namespaces[l.Locale] = l.Namespaces
+	}
+
+	assert.Equal(t, map[string][]string{
+		"en":    {"portal"},
+		"de":    {"portal", "settings"},
+		"pt-BR": {"portal", "consent", "settings"},
+		"pt":    {"portal", "consent", "settings"},
+	}, namespaces)
+
+	assert.Equal(t, []string{
+		"file 'de-DE/portal.json' has the locale 'de-DE' which resolves to the same language as the locale 'de' so they have been merged as the locale 'de'",
+		"file 'pt-BR/portal.json' has the locale 'pt-BR' which resolves to the same language as the locale 'PT-BR' so they have been merged as the locale 'pt-BR'",
+		"file 'pt-br/settings.json' has the locale 'pt-br' which resolves to the same language as the locale 'PT-BR' so they have been merged as the locale 'pt-BR'",
+	}, languages.Warnings)
+
+	assert.EqualError(t, getLanguagesServedErr(languages), "the locale 'de' has locale files in the directories 'de', 'de-DE' but only a single directory can be served for each locale")
+}
+
+func TestGetLanguagesServedErr(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     fstest.MapFS
+		expected string
+	}{
+		{
+			"ShouldAllowSingleDirectories",
+			fstest.MapFS{
+				"en/portal.json":      {Data: []byte("{}")},
+				"de-DE/portal.json":   {Data: []byte("{}")},
+				"de-DE/settings.json": {Data: []byte("{}")},
+				"pt-br/portal.json":   {Data: []byte("{}")},
+			},
+			"",
+		},
+		{
+			"ShouldErrorMergedSpellings",
+			fstest.MapFS{
+				"en/portal.json":      {Data: []byte("{}")},
+				"pt-BR/portal.json":   {Data: []byte("{}")},
+				"PT-BR/settings.json": {Data: []byte("{}")},
+			},
+			"the locale 'pt-BR' has locale files in the directories 'PT-BR', 'pt-BR' but only a single directory can be served for each locale",
+		},
+		{
+			"ShouldErrorMergedSpellingsWhichAreNotCanonical",
+			fstest.MapFS{
+				"en/portal.json":      {Data: []byte("{}")},
+				"pt-br/portal.json":   {Data: []byte("{}")},
+				"PT-BR/settings.json": {Data: []byte("{}")},
+			},
+			"the locale 'pt-BR' has locale files in the directories 'PT-BR', 'pt-br' but only a single directory can be served for each locale",
+		},
+		{
+			"ShouldErrorFlatLayout",
+			fstest.MapFS{
+				"en/portal.json":   {Data: []byte("{}")},
+				"fr/portal.json":   {Data: []byte("{}")},
+				"fr.settings.json": {Data: []byte("{}")},
+			},
+			"the locale 'fr' has the locale file 'fr.settings.json' which can't be served as only locale files in the directory of the locale are served",
+		},
+		{
+			"ShouldErrorYAML",
+			fstest.MapFS{
+				"en/portal.json":   {Data: []byte("{}")},
+				"fr/portal.json":   {Data: []byte("{}")},
+				"fr/settings.yaml": {Data: []byte("a: a\n")},
+			},
+			"the locale 'fr' has the locale file 'fr/settings.yaml' which can't be served as only JSON locale files are served",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			languages, err := getLanguagesFromFS(tc.have, languagesOptions{})
+
+			require.NoError(t, err)
+
+			if tc.expected == "" {
+				assert.NoError(t, getLanguagesServedErr(languages))
+			} else {
+				assert.EqualError(t, getLanguagesServedErr(languages), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetLanguagesFromFSShouldSetCanonical(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"pt_br/portal.json": {Data: []byte("{}")},
+		"zh-tw/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", languages.Defaults.Language.Canonical)
+
+	canonical := map[string]string{}
+
+	for _, l := range languages.Languages {
+		canonical[l.Locale] = l.Canonical
+	}
+
+	assert.Equal(t, map[string]string{
+		"en":      "en",
+		"pt":      "pt",
+		"pt_br":   "pt-BR",
+		"zh-Hant": "zh-Hant",
+		"zh-tw":   "zh-TW",
+	}, canonical)
+}
@@ -43,6 +43,8 @@ const (
 const (
 	pathJSONSchema = "json-schema"
 	extJSON        = ".json"
+	extYAML        = ".yaml"
+	extYML         = ".yml"
 )
 
 const (
@@ -55,6 +57,9 @@ const (
 
 	localeDefault          = "en"
 	localeNamespaceDefault = "portal"
+
+	localeDirectionLTR = "ltr"
+	localeDirectionRTL = "rtl"
 )
 
 const (
@@ -114,6 +119,12 @@ const (
 	cmdFlagFeatureRequest                         = "file.feature-request"
 	cmdFlagBugReport                              = "file.bug-report"
 	cmdFlagVersions                               = "versions"
+	cmdFlagLocaleDefault                          = "locale.default"
+	cmdFlagLocaleNamespaceDefault                 = "locale.namespace.default"
+	cmdFlagLocaleCoverageMinimum                  = "locale.coverage.minimum"
+	cmdFlagLocaleIgnore                           = "locale.ignore"
+	cmdFlagLocaleHidden                           = "locale.hidden"
+	cmdFlagLocaleDisplay                          = "locale.display"
 
 	cmdFlagExclude           = "exclude"
 	cmdFlagVersionCount      = "version-count"
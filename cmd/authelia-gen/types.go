@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
 	"strings"
 	"time"
 
 	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
 )
 
 type tmplIssueTemplateData struct {
@@ -97,6 +99,10 @@ type Languages struct {
 	Defaults   DefaultsLanguages `json:"defaults"`
 	Namespaces []string          `json:"namespaces"`
 	Languages  []Language        `json:"languages"`
+
+	Aliases  map[string]string `json:"-"`
+	Skipped  []string          `json:"-"`
+	Warnings []string          `json:"-"`
 }
 
 type DefaultsLanguages struct {
@@ -108,10 +114,373 @@ type DefaultsLanguages struct {
 type Language struct {
 	Display    string   `json:"display"`
 	Locale     string   `json:"locale"`
+	Canonical  string   `json:"canonical"`
+	Direction  string   `json:"direction"`
 	Namespaces []string `json:"namespaces,omitempty"`
 	Fallbacks  []string `json:"fallbacks,omitempty"`
+	Plurals    []string `json:"plurals,omitempty"`
+
+	Coverage *LanguageCoverage `json:"coverage,omitempty"`
+	Hidden   bool              `json:"hidden,omitempty"`
 
 	Tag language.Tag `json:"-"`
+
+	files    map[string]languageFile
+	spelling string
+}
+
+// clone returns a deep copy of the language.
+func (l Language) clone() Language {
+	l.Namespaces = cloneStrings(l.Namespaces)
+	l.Fallbacks = cloneStrings(l.Fallbacks)
+	l.Plurals = cloneStrings(l.Plurals)
+
+	if l.Coverage != nil {
+		coverage := &LanguageCoverage{Percent: l.Coverage.Percent, Namespaces: make(map[string]float64, len(l.Coverage.Namespaces))}
+
+		for ns, percent := range l.Coverage.Namespaces {
+			coverage.Namespaces[ns] = percent
+		}
+
+		l.Coverage = coverage
+	}
+
+	if l.files != nil {
+		files := make(map[string]languageFile, len(l.files))
+
+		for ns, file := range l.files {
+			files[ns] = file
+		}
+
+		l.files = files
+	}
+
+	return l
+}
+
+// LanguageCoverage is the docs json model for the translation coverage of a language relative to the default language.
+type LanguageCoverage struct {
+	Percent    float64            `json:"percent"`
+	Namespaces map[string]float64 `json:"namespaces"`
+}
+
+// LanguagesStats is the aggregate statistics of the languages used to summarize the translation health.
+type LanguagesStats struct {
+	Languages   int                         `json:"languages"`
+	Namespaces  int                         `json:"namespaces"`
+	Backed      []string                    `json:"backed"`
+	Synthesized []string                    `json:"synthesized"`
+	Coverage    map[string]LanguageCoverage `json:"coverage"`
+}
+
+// LanguageMissingNamespaces is the namespaces a language is missing relative to the default language.
+type LanguageMissingNamespaces struct {
+	Locale     string   `json:"locale"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// LanguageKeysReport is the message keys of a namespace of a language which are missing or extra relative to the same
+// namespace of the default language.
+type LanguageKeysReport struct {
+	Locale    string   `json:"locale"`
+	Namespace string   `json:"namespace"`
+	Missing   []string `json:"missing,omitempty"`
+	Extra     []string `json:"extra,omitempty"`
+}
+
+// Default returns the Language for the configured default locale. A Language is synthesized from the defaults with the
+// default locale as its only fallback if the default locale is not one of the languages.
+func (l *Languages) Default() Language {
+	if lang, ok := l.lookupLocale(l.Defaults.Language.Locale); ok {
+		return lang
+	}
+
+	lang := l.Defaults.Language
+
+	lang.Fallbacks = []string{lang.Locale}
+
+	return lang
+}
+
+// BestMatch returns the available Language which best matches the provided Accept-Language header value, respecting
+// the quality values, wildcards, and aliases. Only languages backed by locale files are candidates so a synthesized
+// parent locale is never returned in place of an actual translation. The default Language is returned if the header is
+// empty, malformed, or has no acceptable match. A match with low confidence such as zh-TW for zh isn't acceptable.
+func (l *Languages) BestMatch(acceptLanguage string) Language {
+	tags, _, err := language.ParseAcceptLanguage(normalizeAcceptLanguage(acceptLanguage))
+	if err != nil || len(tags) == 0 {
+		return l.Default()
+	}
+
+	for i, tag := range tags {
+		if _, ok := l.lookup(tag, true); ok {
+			continue
+		}
+
+		if aliased, ok := l.alias(tag); ok {
+			tags[i] = aliased
+		}
+	}
+
+	// The first supported tag is the one the matcher falls back to, so the default is always first.
+	supported := []language.Tag{l.Default().Tag}
+	matches := []Language{l.Default()}
+
+	for _, lang := range l.Languages {
+		if lang.Locale == l.Defaults.Language.Locale || len(lang.files) == 0 {
+			continue
+		}
+
+		supported = append(supported, lang.Tag)
+		matches = append(matches, lang)
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(tags...)
+	if confidence <= language.Low {
+		return l.Default()
+	}
+
+	return matches[index]
+}
+
+// Resolve returns the available Language for the provided locale. The locale is first matched exactly, then its alias
+// is resolved if the locale is not available, and finally the parents of the locale are matched in order. Only
+// languages backed by locale files are returned, and false is returned if there is no available Language or the
+// locale is malformed.
+func (l *Languages) Resolve(locale string) (Language, bool) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return Language{}, false
+	}
+
+	return l.resolve(tag, true)
+}
+
+// ByLocale returns the Language for the provided locale. It's matched the same way as ByTag once parsed, and false is
+// returned if the locale is malformed.
+func (l *Languages) ByLocale(locale string) (Language, bool) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return Language{}, false
+	}
+
+	return l.ByTag(tag)
+}
+
+// ByTag returns the Language for the provided tag. Unlike Resolve synthesized parent languages are also returned. The
+// tag is first matched exactly, then its alias is resolved, and finally the parents of the tag are matched in order.
+func (l *Languages) ByTag(tag language.Tag) (Language, bool) {
+	return l.resolve(tag, false)
+}
+
+// ByLocaleOrDefault is the same as ByLocale except the default Language is returned when no Language is found.
+func (l *Languages) ByLocaleOrDefault(locale string) Language {
+	if lang, ok := l.ByLocale(locale); ok {
+		return lang
+	}
+
+	return l.Default()
+}
+
+// ByTagOrDefault is the same as ByTag except the default Language is returned when no Language is found.
+func (l *Languages) ByTagOrDefault(tag language.Tag) Language {
+	if lang, ok := l.ByTag(tag); ok {
+		return lang
+	}
+
+	return l.Default()
+}
+
+// ResolveNamespace returns the ordered locales to load the provided namespace from for the provided locale. The locale
+// is found the same way as ByLocaleOrDefault, and it's followed by its fallbacks which end with the default locale.
+// Only the locales with a locale file for the namespace are returned.
+func (l *Languages) ResolveNamespace(locale, namespace string) (locales []string) {
+	lang := l.ByLocaleOrDefault(locale)
+
+	seen := map[string]bool{}
+
+	for _, candidate := range append([]string{lang.Locale}, lang.Fallbacks...) {
+		if seen[candidate] {
+			continue
+		}
+
+		seen[candidate] = true
+
+		if fallback, ok := l.lookupLocale(candidate); ok {
+			if _, ok = fallback.files[namespace]; ok {
+				locales = append(locales, candidate)
+			}
+		}
+	}
+
+	return locales
+}
+
+// DisplayIn returns a copy of the languages with the display name of each in the language with the provided tag. For
+// example the display name of the de locale is German when the tag is en, and Deutsch when the tag is de. The display
+// name is left as is when it is not available in the language with the provided tag.
+func (l *Languages) DisplayIn(tag language.Tag) (languages []Language) {
+	namer := display.Tags(tag)
+
+	languages = make([]Language, len(l.Languages))
+
+	for i, lang := range l.Languages {
+		languages[i] = lang.clone()
+
+		if namer == nil {
+			continue
+		}
+
+		if name := namer.Name(lang.Tag); name != "" {
+			languages[i].Display = name
+		}
+	}
+
+	return languages
+}
+
+// MissingNamespaces returns the namespaces present in the default language which are missing from each language backed
+// by locale files, ordered by locale. Languages which are not missing any namespaces are omitted. Synthesized parent
+// languages are not backed by any locale files and instead rely on their fallbacks so they are never reported.
+func (l *Languages) MissingNamespaces() (missing []LanguageMissingNamespaces) {
+	namespaces := l.Default().Namespaces
+
+	for _, lang := range l.Languages {
+		if len(lang.files) == 0 {
+			continue
+		}
+
+		var absent []string
+
+		for _, ns := range namespaces {
+			if _, ok := lang.files[ns]; !ok {
+				absent = append(absent, ns)
+			}
+		}
+
+		if len(absent) != 0 {
+			missing = append(missing, LanguageMissingNamespaces{Locale: lang.Locale, Namespaces: absent})
+		}
+	}
+
+	return missing
+}
+
+// normalizeAcceptLanguage converts the extended language ranges in an Accept-Language header value to basic language
+// ranges which can be parsed, i.e. en-* becomes en. A range which starts with a wildcard followed by other subtags is
+// removed as it can't be expressed as a basic language range, and the lone * wildcard is left as is.
+func normalizeAcceptLanguage(value string) string {
+	ranges := strings.Split(value, ",")
+
+	normalized := make([]string, 0, len(ranges))
+
+	for _, r := range ranges {
+		tag, params, found := strings.Cut(strings.TrimSpace(r), ";")
+
+		subtags := strings.Split(strings.TrimSpace(tag), "-")
+
+		if len(subtags) > 1 && subtags[0] == "*" {
+			continue
+		}
+
+		basic := subtags[:1]
+
+		for _, subtag := range subtags[1:] {
+			if subtag != "*" {
+				basic = append(basic, subtag)
+			}
+		}
+
+		tag = strings.Join(basic, "-")
+
+		if found {
+			tag += ";" + params
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	return strings.Join(normalized, ", ")
+}
+
+// resolve returns the Language for the provided tag, its alias, or the first of its parents in that order, optionally
+// only considering languages backed by locale files.
+func (l *Languages) resolve(tag language.Tag, backed bool) (Language, bool) {
+	if lang, ok := l.lookup(tag, backed); ok {
+		return lang, true
+	}
+
+	if aliased, ok := l.alias(tag); ok {
+		tag = aliased
+	}
+
+	for ; !tag.IsRoot(); tag = tag.Parent() {
+		if lang, ok := l.lookup(tag, backed); ok {
+			return lang, true
+		}
+	}
+
+	return Language{}, false
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(values)), values...)
+}
+
+// lookup returns the Language with the provided tag, optionally only if it's backed by locale files.
+func (l *Languages) lookup(tag language.Tag, backed bool) (Language, bool) {
+	for _, lang := range l.Languages {
+		if lang.Tag == tag && (!backed || len(lang.files) != 0) {
+			return lang, true
+		}
+	}
+
+	return Language{}, false
+}
+
+// lookupLocale returns the Language with the provided locale.
+func (l *Languages) lookupLocale(locale string) (Language, bool) {
+	for _, lang := range l.Languages {
+		if lang.Locale == locale {
+			return lang, true
+		}
+	}
+
+	return Language{}, false
+}
+
+// alias returns the provided tag with the alias of either the whole tag or its base language applied, keeping the
+// remaining subtags of the tag when the base language is aliased.
+func (l *Languages) alias(tag language.Tag) (language.Tag, bool) {
+	value := tag.String()
+
+	alias, ok := l.Aliases[value]
+	if !ok {
+		base, _ := tag.Base()
+
+		if alias, ok = l.Aliases[base.String()]; !ok {
+			return tag, false
+		}
+
+		alias += strings.TrimPrefix(value, base.String())
+	}
+
+	aliased, err := language.Parse(alias)
+	if err != nil {
+		return tag, false
+	}
+
+	return aliased, true
+}
+
+// languageFile is the source of a namespace for a language.
+type languageFile struct {
+	fsys fs.FS
+	name string
 }
 
 const (
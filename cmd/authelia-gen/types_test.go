@@ -2,8 +2,11 @@ package main
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
 )
 
 func TestLabels(t *testing.T) {
@@ -88,3 +91,388 @@ func TestLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestLanguagesBestMatch(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"de-DE/portal.json": {Data: []byte("{}")},
+		"fr/portal.json":    {Data: []byte("{}")},
+		"pt-BR/portal.json": {Data: []byte("{}")},
+		"zh-CN/portal.json": {Data: []byte("{}")},
+		"zh-TW/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{Aliases: map[string]string{"br": "fr"}})
+
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+	}{
+		{"ShouldMatchExact", "fr", "fr"},
+		{"ShouldMatchAlias", "br-FR, de;q=0.5", "fr"},
+		{"ShouldMatchRegion", "pt-BR", "pt-BR"},
+		{"ShouldMatchParent", "de-AT", "de"},
+		{"ShouldMatchChineseTraditional", "zh-HK", "zh-TW"},
+		{"ShouldMatchTranslationOverSynthesizedParent", "zh", "zh-CN"},
+		{"ShouldMatchQualityValues", "ja;q=0.9, fr;q=0.5, de;q=0.8", "de"},
+		{"ShouldMatchDefaultEmpty", "", "en"},
+		{"ShouldMatchDefaultMalformed", "en-US;q=abc;;", "en"},
+		{"ShouldMatchDefaultNoMatch", "ja, ko", "en"},
+		{"ShouldMatchDefaultWildcard", "*", "en"},
+		{"ShouldMatchDefaultWildcardNoMatch", "ja, *;q=0.5", "en"},
+		{"ShouldMatchBeforeWildcard", "fr, *;q=0.5", "fr"},
+		{"ShouldMatchRangeWildcard", "pt-*", "pt-BR"},
+		{"ShouldMatchRangeWildcardQualityValues", "ja;q=0.9, pt-*;q=0.8", "pt-BR"},
+		{"ShouldMatchRangeWildcardIntermediate", "de-*-AT", "de"},
+		{"ShouldMatchDefaultLeadingWildcard", "*-BR", "en"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := languages.BestMatch(tc.have)
+
+			assert.Equal(t, tc.expected, actual.Locale)
+		})
+	}
+}
+
+func TestLanguagesBestMatchShouldNotMatchLowConfidence(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"zh-TW/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+	}{
+		{"ShouldMatchChineseTraditional", "zh-HK", "zh-TW"},
+		{"ShouldMatchDefaultChinese", "zh", "en"},
+		{"ShouldMatchDefaultChineseRegion", "zh-CN", "en"},
+		{"ShouldMatchDefaultChineseSimplified", "zh-Hans", "en"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, languages.BestMatch(tc.have).Locale)
+		})
+	}
+}
+
+func TestLanguagesDefault(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"en/settings.json":  {Data: []byte("{}")},
+		"de/portal.json":    {Data: []byte("{}")},
+		"pt-BR/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	actual := languages.Default()
+
+	assert.Equal(t, "en", actual.Locale)
+	assert.Equal(t, []string{"portal", "settings"}, actual.Namespaces)
+	assert.Equal(t, []string{"en"}, actual.Fallbacks)
+	assert.Len(t, actual.files, 2)
+
+	languages, err = getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json": {Data: []byte("{}")},
+		"de/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{DefaultLocale: "de"})
+
+	require.NoError(t, err)
+
+	actual = languages.Default()
+
+	assert.Equal(t, "de", actual.Locale)
+	assert.Equal(t, "German", actual.Display)
+	assert.Equal(t, []string{"de"}, actual.Fallbacks)
+
+	languages = &Languages{
+		Defaults: DefaultsLanguages{
+			Language: Language{Display: "English", Locale: "en", Tag: language.English},
+		},
+		Languages: []Language{{Display: "German", Locale: "de", Tag: language.German}},
+	}
+
+	actual = languages.Default()
+
+	assert.Equal(t, "en", actual.Locale)
+	assert.Equal(t, "English", actual.Display)
+	assert.Equal(t, []string{"en"}, actual.Fallbacks)
+	assert.Empty(t, actual.Namespaces)
+	assert.Empty(t, actual.files)
+}
+
+func TestLanguagesResolve(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":      {Data: []byte("{}")},
+		"de/portal.json":      {Data: []byte("{}")},
+		"he/portal.json":      {Data: []byte("{}")},
+		"id/portal.json":      {Data: []byte("{}")},
+		"nb/portal.json":      {Data: []byte("{}")},
+		"pt-BR/portal.json":   {Data: []byte("{}")},
+		"sr-Latn/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{Aliases: map[string]string{"sh": "sr-Latn"}})
+
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+		ok       bool
+	}{
+		{"ShouldResolveExact", "de", "de", true},
+		{"ShouldResolveCase", "PT-br", "pt-BR", true},
+		{"ShouldResolveParent", "de-AT", "de", true},
+		{"ShouldResolveAliasNorwegian", "no", "nb", true},
+		{"ShouldResolveAliasNorwegianRegion", "no-NO", "nb", true},
+		{"ShouldResolveAliasHebrew", "iw", "he", true},
+		{"ShouldResolveAliasIndonesian", "in-ID", "id", true},
+		{"ShouldResolveAliasCustom", "sh", "sr-Latn", true},
+		{"ShouldNotResolveSynthesizedParent", "pt", "", false},
+		{"ShouldNotResolveUnavailable", "ja", "", false},
+		{"ShouldNotResolveMalformed", "abcdefghi", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := languages.Resolve(tc.have)
+
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual.Locale)
+		})
+	}
+
+	languages, err = getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json": {Data: []byte("{}")},
+		"nb/portal.json": {Data: []byte("{}")},
+		"no/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	actual, ok := languages.Resolve("no")
+
+	assert.True(t, ok)
+	assert.Equal(t, "no", actual.Locale)
+}
+
+func TestLanguagesByLocale(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"de/portal.json":    {Data: []byte("{}")},
+		"nb/portal.json":    {Data: []byte("{}")},
+		"pt-BR/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+		ok       bool
+	}{
+		{"ShouldFindExact", "de", "de", true},
+		{"ShouldFindCase", "pt-br", "pt-BR", true},
+		{"ShouldFindSynthesizedParent", "pt", "pt", true},
+		{"ShouldFindParent", "pt-PT", "pt", true},
+		{"ShouldFindAlias", "no", "nb", true},
+		{"ShouldNotFindUnavailable", "ja", "", false},
+		{"ShouldNotFindMalformed", "abcdefghi", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := languages.ByLocale(tc.have)
+
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual.Locale)
+
+			if tc.ok {
+				assert.Equal(t, tc.expected, languages.ByLocaleOrDefault(tc.have).Locale)
+			} else {
+				assert.Equal(t, "en", languages.ByLocaleOrDefault(tc.have).Locale)
+			}
+		})
+	}
+}
+
+func TestLanguagesByTag(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"zh-TW/portal.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	actual, ok := languages.ByTag(language.MustParse("zh-TW"))
+
+	assert.True(t, ok)
+	assert.Equal(t, "zh-TW", actual.Locale)
+
+	actual, ok = languages.ByTag(language.MustParse("zh-Hant-HK"))
+
+	assert.True(t, ok)
+	assert.Equal(t, "zh-Hant", actual.Locale)
+
+	_, ok = languages.ByTag(language.Japanese)
+
+	assert.False(t, ok)
+	assert.Equal(t, "en", languages.ByTagOrDefault(language.Japanese).Locale)
+}
+
+func TestLanguagesResolveNamespace(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":      {Data: []byte("{}")},
+		"en/settings.json":    {Data: []byte("{}")},
+		"en/consent.json":     {Data: []byte("{}")},
+		"pt/portal.json":      {Data: []byte("{}")},
+		"pt/settings.json":    {Data: []byte("{}")},
+		"pt-BR/portal.json":   {Data: []byte("{}")},
+		"zh-TW/settings.json": {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		locale    string
+		namespace string
+		expected  []string
+	}{
+		{"ShouldResolveLocaleAndFallbacks", "pt-BR", "portal", []string{"pt-BR", "pt", "en"}},
+		{"ShouldSkipFallbacksMissingNamespace", "pt-BR", "settings", []string{"pt", "en"}},
+		{"ShouldSkipSynthesizedParents", "zh-TW", "settings", []string{"zh-TW", "en"}},
+		{"ShouldResolveParentOfUnavailable", "pt-PT", "portal", []string{"pt", "en"}},
+		{"ShouldResolveDefault", "en", "consent", []string{"en"}},
+		{"ShouldResolveDefaultForUnavailable", "ja", "portal", []string{"en"}},
+		{"ShouldResolveNoneForUnknownNamespace", "pt-BR", "unknown", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, languages.ResolveNamespace(tc.locale, tc.namespace))
+		})
+	}
+}
+
+func TestLanguagesDisplayIn(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":    {Data: []byte("{}")},
+		"de-DE/portal.json": {Data: []byte("{}")},
+		"fr/portal.json":    {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		have     language.Tag
+		expected map[string]string
+	}{
+		{
+			"ShouldDisplayInEnglish",
+			language.English,
+			map[string]string{"en": "English", "de": "German", "fr": "French"},
+		},
+		{
+			"ShouldDisplayInGerman",
+			language.German,
+			map[string]string{"en": "Englisch", "de": "Deutsch", "fr": "Französisch"},
+		},
+		{
+			"ShouldDisplayInFrench",
+			language.French,
+			map[string]string{"en": "anglais", "de": "allemand", "fr": "français"},
+		},
+		{
+			"ShouldNotDisplayInUndetermined",
+			language.Und,
+			map[string]string{"en": "English", "de": "German", "fr": "French"},
+		},
+		{
+			"ShouldNotDisplayInUnknown",
+			language.Make("zz"),
+			map[string]string{"en": "English", "de": "German", "fr": "French"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := map[string]string{}
+
+			for _, l := range languages.DisplayIn(tc.have) {
+				actual[l.Locale] = l.Display
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	for _, l := range languages.Languages {
+		if l.Locale == "de" {
+			assert.Equal(t, "German", l.Display)
+		}
+	}
+
+	displayed := languages.DisplayIn(language.German)
+
+	displayed[0].Namespaces[0] = "modified"
+	displayed[0].Fallbacks[0] = "modified"
+
+	assert.Equal(t, "portal", languages.Languages[0].Namespaces[0])
+	assert.Equal(t, "en", languages.Languages[0].Fallbacks[0])
+}
+
+func TestLanguagesMissingNamespaces(t *testing.T) {
+	languages, err := getLanguagesFromFS(fstest.MapFS{
+		"en/portal.json":      {Data: []byte("{}")},
+		"en/settings.json":    {Data: []byte("{}")},
+		"en/consent.json":     {Data: []byte("{}")},
+		"de/portal.json":      {Data: []byte("{}")},
+		"de/settings.json":    {Data: []byte("{}")},
+		"de/consent.json":     {Data: []byte("{}")},
+		"fr/portal.json":      {Data: []byte("{}")},
+		"fr/extra.json":       {Data: []byte("{}")},
+		"pt-BR/portal.json":   {Data: []byte("{}")},
+		"pt-BR/consent.json":  {Data: []byte("{}")},
+		"zh-CN/portal.json":   {Data: []byte("{}")},
+		"zh-CN/settings.json": {Data: []byte("{}")},
+		"zh-CN/consent.json":  {Data: []byte("{}")},
+	}, languagesOptions{})
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []LanguageMissingNamespaces{
+		{Locale: "fr", Namespaces: []string{"consent", "settings"}},
+		{Locale: "pt-BR", Namespaces: []string{"settings"}},
+	}, languages.MissingNamespaces())
+}
+
+func TestNormalizeAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected string
+	}{
+		{"ShouldNotAlterBasicRanges", "en-US, fr;q=0.5", "en-US, fr;q=0.5"},
+		{"ShouldNotAlterWildcard", "*", "*"},
+		{"ShouldRemoveWildcardSubtags", "en-*, de-*-CH;q=0.5", "en, de-CH;q=0.5"},
+		{"ShouldRemoveLeadingWildcardRanges", "*-US, fr;q=0.5", "fr;q=0.5"},
+		{"ShouldHandleEmpty", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeAcceptLanguage(tc.have))
+		})
+	}
+}
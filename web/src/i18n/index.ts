@@ -63,7 +63,7 @@ i18n.use(Backend)
             no: ["en"],
             pl: ["en"],
             pt: ["en"],
-            "pt-BR": ["en"],
+            "pt-BR": ["pt", "en"],
             ro: ["en"],
             ru: ["en"],
             sl: ["en"],
@@ -76,7 +76,8 @@ i18n.use(Backend)
             "vi-VN": ["vi", "en"],
             zh: ["en"],
             "zh-CN": ["zh", "en"],
-            "zh-TW": ["en"],
+            "zh-Hant": ["en"],
+            "zh-TW": ["zh-Hant", "en"],
         },
         supportedLngs: [
             "en",
@@ -117,6 +118,7 @@ i18n.use(Backend)
             "vi-VN",
             "zh",
             "zh-CN",
+            "zh-Hant",
             "zh-TW",
         ],
         lowerCaseLng: false,